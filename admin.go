@@ -0,0 +1,207 @@
+package buffer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ ManagedBuffer = &Buffer[int]{}
+
+// ManagedBuffer buffer managed without knowing its data type, implemented by *Buffer[T]
+//
+//	@author kevineluo
+//	@update 2026-10-18 20:21:44
+type ManagedBuffer interface {
+	Stats() Stats
+	Flush(async bool) error
+	Close() error
+}
+
+var _ pausable = &Buffer[int]{}
+
+// pausable buffer which can pause and resume flushing
+type pausable interface {
+	Pause() error
+	Resume() error
+}
+
+// AdminHandler http.Handler for inspecting and controlling buffers, routes under Prefix:
+//
+//	GET  {Prefix}                 list stats of all buffers
+//	GET  {Prefix}/{id}            stats of buffer {id}
+//	POST {Prefix}/{id}/flush      flush buffer synchronously, or asynchronously with ?async=true
+//	POST {Prefix}/{id}/pause      pause flushing, 501 if the buffer can't pause
+//	POST {Prefix}/{id}/resume     resume flushing, 501 if the buffer can't pause
+//	POST {Prefix}/{id}/close      close buffer
+//
+//	@author kevineluo
+//	@update 2026-10-18 20:21:44
+type AdminHandler struct {
+	Prefix  string                 // path prefix the handler is mounted under, default is "/debug/buffers"
+	Buffers func() []ManagedBuffer // return buffers to manage, called for every request
+}
+
+// NewAdminHandler new an AdminHandler mounted under "/debug/buffers", e.g.
+//
+//	http.Handle("/debug/buffers/", buffer.NewAdminHandler(func() []buffer.ManagedBuffer { return buffers }))
+//
+//	@param buffers func() []ManagedBuffer
+//	@return *AdminHandler
+//	@author kevineluo
+//	@update 2026-10-18 20:21:44
+func NewAdminHandler(buffers func() []ManagedBuffer) *AdminHandler {
+	return &AdminHandler{Prefix: "/debug/buffers", Buffers: buffers}
+}
+
+// adminStats Stats in JSON
+type adminStats struct {
+	ID                string     `json:"id"`
+	State             string     `json:"state"`
+	QueueDepth        int        `json:"queue_depth"`
+	ContainerLen      int        `json:"container_len"`
+	ContainerBytes    int        `json:"container_bytes"`
+	LastFlushTime     *time.Time `json:"last_flush_time,omitempty"`
+	LastFlushDuration string     `json:"last_flush_duration"`
+	LastFlushError    string     `json:"last_flush_error,omitempty"`
+	FlushedRecords    int64      `json:"flushed_records"`
+	InFlightFlushes   int        `json:"in_flight_flushes"`
+	DroppedErrors     int64      `json:"dropped_errors"`
+}
+
+func newAdminStats(stats Stats) adminStats {
+	result := adminStats{
+		ID:                stats.ID,
+		State:             stats.State.String(),
+		QueueDepth:        stats.QueueDepth,
+		ContainerLen:      stats.ContainerLen,
+		ContainerBytes:    stats.ContainerBytes,
+		LastFlushDuration: stats.LastFlushDuration.String(),
+		FlushedRecords:    stats.FlushedRecords,
+		InFlightFlushes:   stats.InFlightFlushes,
+		DroppedErrors:     stats.DroppedErrors,
+	}
+	if !stats.LastFlushTime.IsZero() {
+		result.LastFlushTime = &stats.LastFlushTime
+	}
+	if stats.LastFlushError != nil {
+		result.LastFlushError = stats.LastFlushError.Error()
+	}
+	return result
+}
+
+// ServeHTTP implement interface http.Handler
+//
+//	@receiver handler *AdminHandler
+//	@param writer http.ResponseWriter
+//	@param request *http.Request
+//	@author kevineluo
+//	@update 2026-10-18 20:21:44
+func (handler *AdminHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(request.URL.Path, handler.Prefix), "/")
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, "/")
+	}
+
+	buffers := handler.Buffers()
+	switch len(segments) {
+	case 0:
+		if request.Method != http.MethodGet {
+			writeAdminError(writer, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		list := make([]adminStats, 0, len(buffers))
+		for _, buffer := range buffers {
+			list = append(list, newAdminStats(buffer.Stats()))
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+		writeAdminJSON(writer, http.StatusOK, list)
+	case 1, 2:
+		buffer := findBuffer(buffers, segments[0])
+		if buffer == nil {
+			writeAdminError(writer, http.StatusNotFound, "buffer not found: "+segments[0])
+			return
+		}
+		if len(segments) == 1 {
+			if request.Method != http.MethodGet {
+				writeAdminError(writer, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			writeAdminJSON(writer, http.StatusOK, newAdminStats(buffer.Stats()))
+			return
+		}
+		if request.Method != http.MethodPost {
+			writeAdminError(writer, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		handler.act(writer, request, buffer, segments[1])
+	default:
+		writeAdminError(writer, http.StatusNotFound, "not found")
+	}
+}
+
+// act do action on buffer
+//
+//	@receiver handler *AdminHandler
+//	@param writer http.ResponseWriter
+//	@param request *http.Request
+//	@param buffer ManagedBuffer
+//	@param action string
+//	@author kevineluo
+//	@update 2026-10-18 20:21:44
+func (handler *AdminHandler) act(writer http.ResponseWriter, request *http.Request, buffer ManagedBuffer, action string) {
+	var err error
+	switch action {
+	case "flush":
+		err = buffer.Flush(request.URL.Query().Get("async") == "true")
+	case "pause", "resume":
+		pausable, ok := buffer.(pausable)
+		if !ok {
+			writeAdminError(writer, http.StatusNotImplemented, "buffer doesn't support "+action)
+			return
+		}
+		if action == "pause" {
+			err = pausable.Pause()
+		} else {
+			err = pausable.Resume()
+		}
+	case "close":
+		err = buffer.Close()
+	default:
+		writeAdminError(writer, http.StatusBadRequest, "unknown action: "+action)
+		return
+	}
+
+	switch {
+	case errors.Is(err, ErrClosed):
+		writeAdminError(writer, http.StatusConflict, err.Error())
+	case err != nil:
+		writeAdminError(writer, http.StatusInternalServerError, err.Error())
+	default:
+		writeAdminJSON(writer, http.StatusOK, newAdminStats(buffer.Stats()))
+	}
+}
+
+// findBuffer find buffer by Config.ID
+func findBuffer(buffers []ManagedBuffer, id string) ManagedBuffer {
+	for _, buffer := range buffers {
+		if buffer.Stats().ID == id {
+			return buffer
+		}
+	}
+	return nil
+}
+
+func writeAdminJSON(writer http.ResponseWriter, status int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(value)
+}
+
+func writeAdminError(writer http.ResponseWriter, status int, message string) {
+	writeAdminJSON(writer, status, map[string]string{"error": message})
+}
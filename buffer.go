@@ -7,9 +7,15 @@ package buffer
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Kevinello/go-buffer/container"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type (
@@ -18,6 +24,10 @@ type (
 		async bool        // determine the flush is async or not
 		done  chan<- void // for receiving flush done signal
 	}
+	item[T any] struct {
+		data        T
+		spanContext trace.SpanContext // span of the put, linked from the span of flush
+	}
 )
 
 // Buffer is a lock-free buffer
@@ -35,13 +45,33 @@ type Buffer[T any] struct {
 	cancel  context.CancelFunc // used to send close buffer signal
 
 	autoFlushTicker *time.Ticker      // ticker for automate flush data
-	dataChan        chan T            // free lock for async putting data in container
+	dataChan        chan item[T]      // free lock for async putting data in container
 	flushSignalChan chan *flushSignal // channel for flush data signal
-	errChan         chan<- error      // channel for sending error to buffer user
+	pauseSignalChan chan void         // channel for pause and resume signal
+	flushDoneChan   chan void         // async flush done signal, so run updates stats of container
+	errChan         chan error        // channel for sending error to buffer user, never blocks the buffer
+
+	tracer     trace.Tracer
+	linksMutex sync.Mutex
+	links      []trace.Link // spans of data put since last flush
+
+	stats     stats
+	paused    atomic.Bool    // automatic and size-triggered flushes are paused
+	flushWG   sync.WaitGroup // in-flight async flushes
+	runDone   chan struct{}  // closed after run returns
+	done      chan struct{}  // closed after cleanup finishes
+	errsMutex sync.Mutex
+	closeErrs []error // errors after the buffer is closed, returned by Shutdown
+
+	// state of async flush, only accessed by run: at most one async flush is running, and a trigger during it is pending
+	asyncFlushing bool
+	pendingFlush  FlushTrigger
 }
 
-// NewBuffer creates a buffer in type `T`, and start handling data
-// return the buffer and a error channel for user to handle error from putting data and flushing data
+// NewBuffer creates a buffer in type `T`, registers it into Config.Registry, and start handling data
+// return the buffer and a error channel for user to handle error from putting data and flushing data,
+// or ErrDuplicateID if Config.Registry is set and another buffer with the same Config.ID is registered in it and not closed yet.
+// when Config.Registry is nil, the buffer replaces the one with the same ID in DefaultRegistry
 //
 //	@param ctx context.Context
 //	@param container container.Container[T]
@@ -50,8 +80,9 @@ type Buffer[T any] struct {
 //	@return errChan <-chan error
 //	@return err error
 //	@author kevineluo
-//	@update 2023-03-30 01:58:14
+//	@update 2026-10-19 14:25:03
 func NewBuffer[T any](ctx context.Context, container container.Container[T], config Config) (buffer *Buffer[T], errChan <-chan error, err error) {
+	defaultRegistry := config.Registry == nil
 	if err = config.Validate(); err != nil {
 		return
 	}
@@ -61,11 +92,25 @@ func NewBuffer[T any](ctx context.Context, container container.Container[T], con
 		container:       container,
 		context:         subCtx,
 		cancel:          cancel,
-		dataChan:        make(chan T, config.ChanBufSize),
+		dataChan:        make(chan item[T], config.ChanBufSize),
 		flushSignalChan: make(chan *flushSignal),
-		errChan:         make(chan error, 1), // error channel with size 1 to avoid block
+		pauseSignalChan: make(chan void),
+		flushDoneChan:   make(chan void, 1),
+		errChan:         make(chan error, config.ErrChanSize),
+		tracer:          config.TracerProvider.Tracer(InstrumentationName),
+		runDone:         make(chan struct{}),
+		done:            make(chan struct{}),
 	}
 
+	if defaultRegistry {
+		buffer.Registry.replace(buffer)
+	} else if err = buffer.Registry.Register(buffer); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("[NewBuffer] %w", err)
+	}
+	errChan = buffer.errChan
+	buffer.updateContainerStats()
+
 	// wait for context cancellation
 	go buffer.cleanup()
 
@@ -82,10 +127,25 @@ func NewBuffer[T any](ctx context.Context, container container.Container[T], con
 //	@author kevineluo
 //	@update 2023-03-15 11:09:25
 func (buffer *Buffer[T]) Put(data T) error {
+	return buffer.PutContext(context.Background(), data)
+}
+
+// PutContext put data into buffer asynchronously like Put, the span in ctx would be linked from the span of the flush carrying the data
+//
+//	@receiver buffer *Buffer[T]
+//	@param ctx context.Context
+//	@param data T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (buffer *Buffer[T]) PutContext(ctx context.Context, data T) error {
 	if buffer.closed() {
+		buffer.drop(DropReasonClosed, 1)
 		return ErrClosed
 	}
-	buffer.dataChan <- data
+	buffer.dataChan <- item[T]{data: data, spanContext: trace.SpanContextFromContext(ctx)}
+	buffer.Metrics.ObservePut(buffer.ID)
+	buffer.Metrics.SetQueueDepth(buffer.ID, len(buffer.dataChan))
 	return nil
 }
 
@@ -118,6 +178,7 @@ func (buffer *Buffer[T]) Flush(async bool) error {
 }
 
 // Close would gracefully shut down the buffer.
+// it returns immediately while draining and the last flush run in background, use Shutdown to wait for them
 //
 //	@param buffer *Buffer[T]
 //	@return Close
@@ -141,7 +202,7 @@ func (buffer *Buffer[T]) Close() error {
 //	@author kevineluo
 //	@update 2023-03-15 10:34:29
 func (buffer *Buffer[T]) run() {
-	defer close(buffer.errChan)
+	defer close(buffer.runDone)
 	buffer.Logger.Info("buffer start handling data", "ID", buffer.ID)
 
 	buffer.autoFlushTicker = time.NewTicker(buffer.FlushInterval)
@@ -153,70 +214,120 @@ func (buffer *Buffer[T]) run() {
 
 	// main signal monitoring loop for safe buffer life cycle
 	for {
+		dataChan := buffer.dataChan
+		if buffer.paused.Load() && buffer.container.IsFull() {
+			// stop receiving data while paused with a full container, Put would block once dataChan is full
+			dataChan = nil
+		}
+
 		select {
 		case <-buffer.context.Done():
 			// receive buffer close signal, stop running
 			buffer.Logger.Info("[Buffer.run] receive buffer close signal, stop running", "time", time.Now().Format(time.DateTime))
 			return
-		case data := <-buffer.dataChan:
+		case item := <-dataChan:
 			// receive one piece of data
-			buffer.putAndCheck(data)
+			buffer.putAndCheck(item)
 		case <-buffer.autoFlushTicker.C:
+			if buffer.paused.Load() {
+				// tick sent before the ticker is stopped by Pause
+				continue
+			}
 			// automate flush buffer(will temporarily stop the timer)
 			buffer.Logger.Info("[Buffer.run] tick for automate flush data reach, will call container.Flush")
 			buffer.autoFlushTicker.Stop()
 			if buffer.SyncAutoFlush {
-				if err := buffer.container.Flush(); err != nil {
-					buffer.Logger.Error(err, "[Buffer.run] error when call Container.Flush")
-					buffer.errChan <- err
-					buffer.container.Reset()
-				}
+				buffer.flush(FlushTriggerTicker)
 			} else {
-				go func() {
-					if err := buffer.container.Flush(); err != nil {
-						buffer.Logger.Error(err, "[Buffer.run] error when call Container.Flush")
-						buffer.errChan <- err
-						buffer.container.Reset()
-					}
-				}()
+				buffer.goFlush(FlushTriggerTicker)
 			}
 			buffer.autoFlushTicker.Reset(buffer.FlushInterval)
 		case flushSignal := <-buffer.flushSignalChan:
-			// manually flush buffer
-			if err := buffer.container.Flush(); err != nil {
-				buffer.Logger.Error(err, "[Buffer.run] error when call Container.Flush")
-				buffer.errChan <- err
-				buffer.container.Reset()
+			// manually flush buffer, including data put before the flush signal but still waiting in dataChan
+			for pending := len(buffer.dataChan); pending > 0 && !(buffer.paused.Load() && buffer.container.IsFull()); pending-- {
+				buffer.putAndCheck(<-buffer.dataChan)
 			}
+			buffer.flush(FlushTriggerManual)
 			if !flushSignal.async {
 				// send flush done signal for synchronously flush
 				flushSignal.done <- void{}
 			}
+		case <-buffer.pauseSignalChan:
+			buffer.handlePause()
+		case <-buffer.flushDoneChan:
+			buffer.handleFlushDone()
 		}
 	}
 }
 
 func (buffer *Buffer[T]) cleanup() {
 	<-buffer.context.Done()
+	// wait for run and async flushes to stop using container
+	<-buffer.runDone
+	buffer.flushWG.Wait()
 	// receive buffer close signal, clean up buffer and return
 	// clean dataChan(there is no more data send into dataChan), and close all channels
 	for {
 		select {
-		case data := <-buffer.dataChan:
+		case item := <-buffer.dataChan:
 			// receive one piece of data
-			buffer.putAndCheck(data)
+			buffer.putAndCheck(item)
 		default:
 			// call last flush
-			if err := buffer.container.Flush(); err != nil {
-				buffer.Logger.Error(err, "[Buffer.cleanup] error when call Container.Flush")
+			buffer.flush(FlushTriggerClose)
+			// release container's resource(e.g. finish opening file) if it needs
+			if closer, ok := buffer.container.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					buffer.Logger.Error(err, "[Buffer.cleanup] error when call Container.Close")
+					buffer.reportError(&FlushError{ID: buffer.ID, Trigger: FlushTriggerClose, BatchSize: buffer.containerLen(), Attempt: 1, Err: err})
+				}
 			}
 			close(buffer.dataChan)
 			close(buffer.flushSignalChan)
+			close(buffer.errChan)
+			buffer.stats.state.Store(int32(StateClosed))
+			// series of the ID belong to the new buffer when this one has been replaced
+			if buffer.Registry.remove(buffer) {
+				buffer.Metrics.Forget(buffer.ID)
+			}
+			if buffer.Hooks.OnClose != nil {
+				buffer.Hooks.OnClose(buffer.ID, buffer.closeErr())
+			}
+			close(buffer.done)
 			return
 		}
 	}
 }
 
+// Shutdown close the buffer and block until data in channel is drained, in-flight async flushes finish and
+// the last flush completes, or ctx is done. it returns errors of puts, flushes and Container.Close after the buffer is closed.
+// it can be called after Close to wait for the buffer
+//
+//	@receiver buffer *Buffer[T]
+//	@param ctx context.Context
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 20:57:10
+func (buffer *Buffer[T]) Shutdown(ctx context.Context) error {
+	buffer.cancel()
+	select {
+	case <-buffer.done:
+		return buffer.closeErr()
+	case <-ctx.Done():
+		return fmt.Errorf("[Buffer.Shutdown] buffer %s is not drained: %w", buffer.ID, ctx.Err())
+	}
+}
+
+// Done return a channel closed after the buffer is closed, and its data is drained and flushed for the last time
+//
+//	@receiver buffer *Buffer[T]
+//	@return <-chan struct{}
+//	@author kevineluo
+//	@update 2026-10-18 20:38:26
+func (buffer *Buffer[T]) Done() <-chan struct{} {
+	return buffer.done
+}
+
 // closed check if the Buffer is closed
 //
 //	@param buffer *Buffer[T]
@@ -235,34 +346,232 @@ func (buffer *Buffer[T]) closed() bool {
 // putAndCheck put a piece of data into container and flush container when full
 //
 //	@param buffer *Buffer[T]
-//	@param data T
-//	@return error
+//	@param item item[T]
 //	@author kevineluo
-//	@update 2023-03-15 09:46:37
-func (buffer *Buffer[T]) putAndCheck(data T) {
-	if err := buffer.container.Put(data); err != nil {
+//	@update 2026-10-18 19:46:05
+func (buffer *Buffer[T]) putAndCheck(item item[T]) {
+	buffer.addLink(item.spanContext)
+	if err := buffer.container.Put(item.data); err != nil {
 		buffer.Logger.Error(err, "[Buffer.putAndCheck] buffer cannot write message to container")
-		buffer.errChan <- err
+		buffer.drop(DropReasonPutFailed, 1)
+		buffer.reportError(&FlushError{ID: buffer.ID, BatchSize: 1, Attempt: 1, Records: []T{item.data}, Err: err})
 	}
+	buffer.Metrics.SetQueueDepth(buffer.ID, len(buffer.dataChan))
+	buffer.updateContainerStats()
 
-	if buffer.container.IsFull() {
+	if buffer.container.IsFull() && (!buffer.paused.Load() || buffer.closed()) {
 		buffer.Logger.Info("[Buffer.putAndCheck] buffer if full, will call container.Flush")
 		buffer.autoFlushTicker.Stop()
-		if buffer.SyncAutoFlush {
-			if err := buffer.container.Flush(); err != nil {
-				buffer.Logger.Error(err, "[Buffer.putAndCheck] error when call Container.Flush")
-				buffer.errChan <- err
-				buffer.container.Reset()
-			}
+		if buffer.SyncAutoFlush || buffer.closed() {
+			// flush synchronously when cleanup is draining data
+			buffer.flush(FlushTriggerFull)
 		} else {
-			go func() {
-				if err := buffer.container.Flush(); err != nil {
-					buffer.Logger.Error(err, "[Buffer.putAndCheck] error when call Container.Flush")
-					buffer.errChan <- err
-					buffer.container.Reset()
-				}
-			}()
+			buffer.goFlush(FlushTriggerFull)
 		}
 		buffer.autoFlushTicker.Reset(buffer.FlushInterval)
 	}
 }
+
+// flush flush synchronously and update stats of container, must be called by run, or cleanup after run returns
+//
+//	@receiver buffer *Buffer[T]
+//	@param trigger FlushTrigger
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-19 13:06:32
+func (buffer *Buffer[T]) flush(trigger FlushTrigger) (err error) {
+	err = buffer.flushBatch(trigger, buffer.takeLinks(), buffer.containerLen())
+	buffer.updateContainerStats()
+	return
+}
+
+// flushBatch call Container.Flush and retry it at most Config.FlushRetries times when it fails, then record metrics and span of the flush.
+// if it still fails, report the error(see reportError), count dropped data and reset the container, except for the last flush
+// triggered by close and containers keeping failed data by themselves(see container.Keeper).
+// a failed last flush triggered by close counts data kept by container as dropped
+//
+//	@receiver buffer *Buffer[T]
+//	@param trigger FlushTrigger
+//	@param links []trace.Link spans of puts carried by the flush
+//	@param batchSize int length of container taken by the caller before the flush, -1 if unknown
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-19 15:28:43
+func (buffer *Buffer[T]) flushBatch(trigger FlushTrigger, links []trace.Link, batchSize int) (err error) {
+	buffer.stats.inFlightFlushes.Add(1)
+	defer buffer.stats.inFlightFlushes.Add(-1)
+	span := buffer.startFlushSpan(trigger, batchSize, links)
+	info := FlushInfo{ID: buffer.ID, Trigger: trigger, BatchSize: batchSize}
+	if buffer.Hooks.OnFlushStart != nil {
+		buffer.Hooks.OnFlushStart(info)
+	}
+	start := time.Now()
+	for {
+		if err = buffer.container.Flush(); err == nil || info.Retries >= buffer.FlushRetries {
+			break
+		}
+		buffer.Logger.Error(err, "[Buffer.flush] error when call Container.Flush, will retry", "trigger", trigger, "retry", info.Retries+1)
+		buffer.Metrics.ObserveRetry(buffer.ID, trigger)
+		if buffer.Hooks.OnRetry != nil {
+			info.Err = err
+			buffer.Hooks.OnRetry(info)
+		}
+		info.Retries++
+		time.Sleep(buffer.FlushRetryInterval)
+	}
+	latency := time.Since(start)
+	buffer.Metrics.ObserveFlush(buffer.ID, trigger, batchSize, latency, err)
+	buffer.recordFlush(batchSize, start.Add(latency), latency, err)
+	endFlushSpan(span, info.Retries, err)
+	if buffer.Hooks.OnFlushDone != nil {
+		info.Duration, info.Err = latency, err
+		buffer.Hooks.OnFlushDone(info)
+	}
+
+	if err != nil {
+		buffer.Logger.Error(err, "[Buffer.flush] error when call Container.Flush", "trigger", trigger)
+		flushErr := &FlushError{ID: buffer.ID, Trigger: trigger, BatchSize: batchSize, Attempt: info.Retries + 1, Err: err}
+		var partial *container.PartialFlushError[T]
+		if errors.As(err, &partial) {
+			records := make([]T, 0, len(partial.Failed))
+			for _, failed := range partial.Failed {
+				records = append(records, failed.Element)
+			}
+			flushErr.Records = records
+		}
+		buffer.reportError(flushErr)
+		// no flush follows the last one triggered by close, so data kept by container are dropped too
+		kept := trigger != FlushTriggerClose && buffer.keepsFailed()
+		if !kept && trigger != FlushTriggerClose {
+			buffer.container.Reset()
+		}
+		if dropped := flushDropped[T](err, batchSize, kept); dropped > 0 {
+			buffer.drop(DropReasonFlushFailed, dropped)
+		}
+	}
+	return
+}
+
+// goFlush flush asynchronously, tracked by flushWG so cleanup waits for it. the batch size is taken by the caller(run),
+// and stats of container are updated by run after the flush is done. async flushes run one at a time,
+// a trigger during the running one is kept pending and handled when it's done(see handleFlushDone), must be called by run
+//
+//	@receiver buffer *Buffer[T]
+//	@param trigger FlushTrigger
+//	@author kevineluo
+//	@update 2026-10-19 14:10:37
+func (buffer *Buffer[T]) goFlush(trigger FlushTrigger) {
+	if buffer.asyncFlushing {
+		buffer.pendingFlush = trigger
+		return
+	}
+	buffer.asyncFlushing = true
+	links, batchSize := buffer.takeLinks(), buffer.containerLen()
+	buffer.flushWG.Add(1)
+	go func() {
+		defer buffer.flushWG.Done()
+		buffer.flushBatch(trigger, links, batchSize)
+		// never blocks since only one async flush is running
+		buffer.flushDoneChan <- void{}
+	}()
+}
+
+// handleFlushDone update stats of container after an async flush is done, and start the pending flush if any, must be called by run
+//
+//	@receiver buffer *Buffer[T]
+//	@author kevineluo
+//	@update 2026-10-19 14:10:37
+func (buffer *Buffer[T]) handleFlushDone() {
+	buffer.asyncFlushing = false
+	buffer.updateContainerStats()
+	if trigger := buffer.pendingFlush; trigger != "" && !buffer.paused.Load() {
+		buffer.pendingFlush = ""
+		buffer.goFlush(trigger)
+	}
+}
+
+// reportError pass error to Config.ErrorHandler, then send it to errChan without blocking(counted in Stats.DroppedErrors
+// when errChan is full), or keep it for Shutdown after the buffer is closed
+//
+//	@receiver buffer *Buffer[T]
+//	@param err *FlushError
+//	@author kevineluo
+//	@update 2026-10-18 21:26:30
+func (buffer *Buffer[T]) reportError(err *FlushError) {
+	if buffer.ErrorHandler != nil {
+		buffer.ErrorHandler(*err)
+	}
+	if buffer.closed() {
+		buffer.errsMutex.Lock()
+		defer buffer.errsMutex.Unlock()
+		buffer.closeErrs = append(buffer.closeErrs, err)
+		return
+	}
+	select {
+	case buffer.errChan <- err:
+	default:
+		buffer.stats.droppedErrors.Add(1)
+		buffer.Logger.Info("[Buffer.reportError] error channel is full, drop the error", "error", err.Error())
+	}
+}
+
+// closeErr return joined errors after the buffer is closed
+//
+//	@receiver buffer *Buffer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 21:12:48
+func (buffer *Buffer[T]) closeErr() error {
+	buffer.errsMutex.Lock()
+	defer buffer.errsMutex.Unlock()
+	return errors.Join(buffer.closeErrs...)
+}
+
+// containerLen return the number of data held by container, or -1 if the container doesn't implement `Len() int`
+//
+//	@receiver buffer *Buffer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 19:28:40
+func (buffer *Buffer[T]) containerLen() int {
+	if lener, ok := buffer.container.(interface{ Len() int }); ok {
+		return lener.Len()
+	}
+	return -1
+}
+
+// keepsFailed check if the container keeps data failed in flush by itself, see container.Keeper
+//
+//	@receiver buffer *Buffer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (buffer *Buffer[T]) keepsFailed() bool {
+	_, ok := buffer.container.(container.Keeper)
+	return ok
+}
+
+// flushDropped count data lost by a failed flush, from the batch size taken before Container.Flush and the data kept by container:
+// failed elements of *container.PartialFlushError which are not requeued, or requeued but reset,
+// Dropped of *container.KeptError, or the whole batch for other errors
+//
+//	@param err error returned by Container.Flush
+//	@param batchSize int length of container before Container.Flush, -1 if unknown
+//	@param kept bool the container keeps failed data by itself, so it's not reset
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-19 12:14:20
+func flushDropped[T any](err error, batchSize int, kept bool) int {
+	var partial *container.PartialFlushError[T]
+	if errors.As(err, &partial) {
+		if kept {
+			return len(partial.Failed) - partial.Requeued()
+		}
+		return len(partial.Failed)
+	}
+	var keptErr *container.KeptError
+	if kept && errors.As(err, &keptErr) {
+		return keptErr.Dropped
+	}
+	return batchSize
+}
@@ -0,0 +1,114 @@
+// go-buffer-gen generate zero-reflection `Insert(proto.Input)` method and newInputFunc for ClickHouseContainer.
+//
+// from a tagged Go struct, usually with go:generate in the file defining the struct:
+//
+//	//go:generate go run github.com/Kevinello/go-buffer/cmd/go-buffer-gen -type Event
+//
+// from the output of `DESCRIBE TABLE events FORMAT TSV`, the struct would be defined in generated file too:
+//
+//	clickhouse-client -q "DESCRIBE TABLE events FORMAT TSV" | go-buffer-gen -type Event -describe - -package events
+//
+// LowCardinality(Nullable(X)) columns are not supported since ch-go can't insert them, exclude them with -exclude,
+// and the lowcardinality tag option is rejected on pointer fields for the same reason
+//
+//	@update 2026-10-19 13:31:50
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/Kevinello/go-buffer/internal/chgen"
+)
+
+func main() {
+	var (
+		typeName    = flag.String("type", "", "name of the row type, required")
+		source      = flag.String("source", os.Getenv("GOFILE"), "Go file defining the struct, default is $GOFILE set by go generate")
+		describe    = flag.String("describe", "", "file holding output of `DESCRIBE TABLE ... FORMAT TSV`, - for stdin; the struct is generated from it when set")
+		packageName = flag.String("package", os.Getenv("GOPACKAGE"), "package of generated file in describe mode, default is $GOPACKAGE set by go generate")
+		exclude     = flag.String("exclude", "", "comma separated columns to skip in describe mode, e.g. columns with default value or of unsupported LowCardinality(Nullable(X)) type")
+		output      = flag.String("output", "", "output file, default is <type>_clickhouse_gen.go in the directory of source")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: go-buffer-gen -type T [-source file.go | -describe file -package p] [-exclude columns] [-output file]")
+		fmt.Fprintln(flag.CommandLine.Output(), "limitation: LowCardinality(Nullable(X)) columns are not supported since ch-go can't insert them")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*typeName, *source, *describe, *packageName, *exclude, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "go-buffer-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(typeName, source, describe, packageName, exclude, output string) (err error) {
+	if typeName == "" {
+		return fmt.Errorf("-type is required")
+	}
+
+	var table chgen.Table
+	dir := "."
+	if describe != "" {
+		if packageName == "" {
+			return fmt.Errorf("-package is required in describe mode")
+		}
+		reader := io.Reader(os.Stdin)
+		if describe != "-" {
+			file, err := os.Open(describe)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			reader = file
+		}
+		var excluded []string
+		if exclude != "" {
+			excluded = strings.Split(exclude, ",")
+		}
+		if table, err = chgen.ParseDescribe(reader, typeName, excluded); err != nil {
+			return err
+		}
+		table.Package = packageName
+	} else {
+		if source == "" {
+			return fmt.Errorf("-source is required when not running by go generate")
+		}
+		src, err := os.ReadFile(source)
+		if err != nil {
+			return err
+		}
+		if table, err = chgen.ParseStruct(source, src, typeName); err != nil {
+			return err
+		}
+		dir = filepath.Dir(source)
+	}
+
+	code, err := chgen.Generate(table)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = filepath.Join(dir, snakeCase(typeName)+"_clickhouse_gen.go")
+	}
+	return os.WriteFile(output, code, 0o644)
+}
+
+// snakeCase convert "HTTPEvent" into "http_event"
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			builder.WriteByte('_')
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
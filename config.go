@@ -8,6 +8,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -23,6 +24,17 @@ type Config struct {
 	FlushInterval    time.Duration // automate flush data every [flushInterval] duration
 	SyncAutoFlush    bool          // determine the buffer will automate flush asynchronously or synchronously, default is false -- async flush
 
+	FlushRetries       int           // times to call Container.Flush again when it fails, default is 0 -- no retry
+	FlushRetryInterval time.Duration // interval between retries of failed flush, default is 100ms
+	Metrics            Metrics       // record the state of buffer, e.g. PrometheusMetrics or OTelMetrics, default records nothing
+
+	TracerProvider trace.TracerProvider // start span buffer.flush for every flush, default is a no-op provider
+	Registry       *Registry            // registry the buffer registers into by ID until it's closed, default is DefaultRegistry, where a buffer replaces the one with the same ID instead of failing with ErrDuplicateID
+	Hooks          Hooks                // callbacks of buffer lifecycle
+
+	ErrorHandler func(err FlushError) // called with every error of put and flush, synchronously by goroutines of the buffer
+	ErrChanSize  int                  // capacity of the error channel returned by NewBuffer, errors are dropped when it's full, default is 1
+
 	Logger   *logr.Logger // third-part logger implement logr.LogSinker, default using zapr.Logger
 	LogLevel int          // used when Config.logger is nil, follow the zap style level(https://pkg.go.dev/go.uber.org/zap@v1.24.0/zapcore#Level), setting the log level for zapr.Logger(config.logLevel should be in range[-1, 5], default is 0 -- InfoLevel)
 }
@@ -43,6 +55,25 @@ func (config *Config) Validate() (err error) {
 	if config.FlushInterval == 0 {
 		config.FlushInterval = 15 * time.Second
 	}
+	if config.FlushRetries < 0 {
+		err = fmt.Errorf("[config.Check] found invalid config.FlushRetries: %d, config.FlushRetries should not be negative", config.FlushRetries)
+		return
+	}
+	if config.ErrChanSize <= 0 {
+		config.ErrChanSize = 1
+	}
+	if config.FlushRetryInterval == 0 {
+		config.FlushRetryInterval = 100 * time.Millisecond
+	}
+	if config.Metrics == nil {
+		config.Metrics = nopMetrics{}
+	}
+	if config.Registry == nil {
+		config.Registry = DefaultRegistry
+	}
+	if config.TracerProvider == nil {
+		config.TracerProvider = trace.NewNoopTracerProvider()
+	}
 	if config.Logger == nil {
 		var cfg zap.Config
 		level := zapcore.Level(config.LogLevel)
@@ -0,0 +1,143 @@
+package container
+
+import "sync"
+
+// batch hold the pending elements of a Container which flushes its data as a whole slice.
+// it's thread safe, so Put can be called by Buffer while an async Flush is sending the taken elements,
+// and flushing is held by Flush and Close of the container, so they run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+type batch[T any] struct {
+	mutex     sync.Mutex
+	elements  []T         // pending elements
+	flushSize int         // determine the flush size
+	size      func(T) int // optional, size of an element in bytes
+	bytes     int         // total size of pending elements, only counted when size is set
+
+	flushing sync.Mutex // held by Flush and Close of the container
+}
+
+// newBatch new a batch with flushSize
+//
+//	@param flushSize int
+//	@return batch[T]
+//	@author kevineluo
+//	@update 2026-10-18 09:12:40
+func newBatch[T any](flushSize int) batch[T] {
+	return batch[T]{
+		elements:  make([]T, 0, flushSize),
+		flushSize: flushSize,
+	}
+}
+
+// newSizedBatch new a batch with flushSize, which counts bytes of pending elements by size
+//
+//	@param flushSize int
+//	@param size func(T) int
+//	@return batch[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+func newSizedBatch[T any](flushSize int, size func(T) int) batch[T] {
+	return batch[T]{
+		elements:  make([]T, 0, flushSize),
+		flushSize: flushSize,
+		size:      size,
+	}
+}
+
+// put append an element into batch
+//
+//	@receiver b *batch[T]
+//	@param element T
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+func (b *batch[T]) put(element T) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.elements = append(b.elements, element)
+	if b.size != nil {
+		b.bytes += b.size(element)
+	}
+}
+
+// isFull return true if the batch reach its flush size
+//
+//	@receiver b *batch[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-19 14:02:11
+func (b *batch[T]) isFull() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.elements) >= b.flushSize
+}
+
+// len return the number of pending elements
+//
+//	@receiver b *batch[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-19 14:02:11
+func (b *batch[T]) len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.elements)
+}
+
+// sizeBytes return the total size of pending elements in bytes, -1 if the batch doesn't count it
+//
+//	@receiver b *batch[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+func (b *batch[T]) sizeBytes() int {
+	if b.size == nil {
+		return -1
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.bytes
+}
+
+// take return all pending elements and reset the batch, the returned slice is owned by caller
+//
+//	@receiver b *batch[T]
+//	@return []T
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+func (b *batch[T]) take() []T {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	elements := b.elements
+	b.elements, b.bytes = make([]T, 0, b.flushSize), 0
+	return elements
+}
+
+// restore put back elements which fail to be flushed, ahead of the elements put after taking
+//
+//	@receiver b *batch[T]
+//	@param elements []T
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+func (b *batch[T]) restore(elements []T) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.elements = append(elements, b.elements...)
+	if b.size != nil {
+		for _, element := range elements {
+			b.bytes += b.size(element)
+		}
+	}
+}
+
+// reset drop all pending elements
+//
+//	@receiver b *batch[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+func (b *batch[T]) reset() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.elements, b.bytes = make([]T, 0, b.flushSize), 0
+}
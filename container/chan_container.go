@@ -0,0 +1,176 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	_ Container[int] = &ChanContainer[int]{}
+	_ Keeper         = &ChanContainer[int]{}
+)
+
+// ChanMode decide what ChanContainer does when the channel is not ready to receive
+type ChanMode int
+
+const (
+	ChanBlock   ChanMode = iota // block until the batch is received
+	ChanDrop                    // drop the batch and return ErrChanBatchDropped immediately
+	ChanTimeout                 // wait for at most Timeout, then keep the batch for next Flush and return ErrChanTimeout
+)
+
+var (
+	// ErrChanBatchDropped the batch is dropped because the channel is not ready to receive(ChanDrop)
+	ErrChanBatchDropped = errors.New("batch dropped since channel is not ready")
+	// ErrChanTimeout the channel is not ready to receive within Timeout(ChanTimeout)
+	ErrChanTimeout = errors.New("timeout waiting for channel to receive batch")
+)
+
+// ChanConfig ChanContainer Config
+//
+//	@author kevineluo
+//	@update 2026-10-18 17:38:05
+type ChanConfig[T any] struct {
+	FlushSize int           // container would be full when holding FlushSize elements
+	Chan      chan<- []T    // every batch would be sent to Chan, ChanContainer never closes it
+	Mode      ChanMode      // what to do when Chan is not ready, default is ChanBlock
+	Timeout   time.Duration // max wait in ChanTimeout mode
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *ChanConfig[T]
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 17:38:05
+func (config *ChanConfig[T]) Validate() (err error) {
+	if config.FlushSize <= 0 {
+		return fmt.Errorf("[ChanConfig.Validate] invalid FlushSize: %d, FlushSize should be positive", config.FlushSize)
+	}
+	if config.Chan == nil {
+		return errors.New("[ChanConfig.Validate] Chan should not be nil")
+	}
+	switch config.Mode {
+	case ChanBlock, ChanDrop:
+	case ChanTimeout:
+		if config.Timeout <= 0 {
+			return fmt.Errorf("[ChanConfig.Validate] invalid Timeout: %v, Timeout should be positive in ChanTimeout mode", config.Timeout)
+		}
+	default:
+		return fmt.Errorf("[ChanConfig.Validate] unsupported Mode: %d", config.Mode)
+	}
+	return
+}
+
+// ChanContainer emit every batch as a []T onto a channel, so a Buffer can feed a worker pool or the next stage of a pipeline.
+// the receiver owns the emitted slice, it would never be touched by the container again.
+// Put can be called while an async Flush is running, flushes run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 14:02:11
+type ChanContainer[T any] struct {
+	config ChanConfig[T]
+	batch  batch[T]
+}
+
+// NewChanContainer new a ChanContainer
+//
+//	@param config ChanConfig[T]
+//	@return *ChanContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:38:05
+func NewChanContainer[T any](config ChanConfig[T]) (*ChanContainer[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &ChanContainer[T]{
+		config: config,
+		batch:  newBatch[T](config.FlushSize),
+	}, nil
+}
+
+// Put implement interface Container
+//
+//	@receiver container *ChanContainer[T]
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:38:05
+func (container *ChanContainer[T]) Put(element T) error {
+	container.batch.put(element)
+	return nil
+}
+
+// Flush implement interface Container, send pending elements onto the channel according to Mode
+//
+//	@receiver container *ChanContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 11:27:14
+func (container *ChanContainer[T]) Flush() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	if container.batch.len() == 0 {
+		return nil
+	}
+	elements := container.batch.take()
+
+	switch container.config.Mode {
+	case ChanDrop:
+		select {
+		case container.config.Chan <- elements:
+		default:
+			return fmt.Errorf("[ChanContainer.Flush] %d elements: %w", len(elements), ErrChanBatchDropped)
+		}
+	case ChanTimeout:
+		timer := time.NewTimer(container.config.Timeout)
+		defer timer.Stop()
+		select {
+		case container.config.Chan <- elements:
+		case <-timer.C:
+			container.batch.restore(elements)
+			return &KeptError{Err: fmt.Errorf("[ChanContainer.Flush] %w after %v", ErrChanTimeout, container.config.Timeout)}
+		}
+	default:
+		container.config.Chan <- elements
+	}
+	return nil
+}
+
+// IsFull implement interface Container
+//
+//	@receiver container *ChanContainer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 17:38:05
+func (container *ChanContainer[T]) IsFull() bool {
+	return container.batch.isFull()
+}
+
+// Reset implement interface Container
+//
+//	@receiver container *ChanContainer[T]
+//	@author kevineluo
+//	@update 2026-10-18 17:38:05
+func (container *ChanContainer[T]) Reset() {
+	container.batch.reset()
+}
+
+// KeepFailed implement interface Keeper, a batch timed out with ChanTimeout is kept in the container
+//
+//	@receiver container *ChanContainer[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *ChanContainer[T]) KeepFailed() {}
+
+// Len return the number of pending elements
+//
+//	@receiver container *ChanContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 17:38:05
+func (container *ChanContainer[T]) Len() int {
+	return container.batch.len()
+}
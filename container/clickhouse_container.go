@@ -2,13 +2,20 @@ package container
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/ClickHouse/ch-go"
 	"github.com/ClickHouse/ch-go/chpool"
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/google/uuid"
 )
 
-var _ Container[ClickHouseRow] = &ClickHouseContainer{}
+var (
+	_ Container[ClickHouseRow] = &ClickHouseContainer{}
+	_ Keeper                   = &ClickHouseContainer{}
+)
 
 type ClickHouseRow interface {
 	Insert(proto.Input) error
@@ -22,9 +29,163 @@ type ClickHouseContainer struct {
 	cols     proto.Input
 
 	newInputFunc func() proto.Input
+	options      clickHouseOptions
+	instance     string            // random id of the container in deduplication tokens and query ids
+	seq          uint64            // sequence number of flushed blocks
+	retryQueue   []clickHouseBlock // failed blocks waiting to be sent again, oldest first
+}
+
+// defaultClickHouseRetryQueueSize default max number of failed blocks held by ClickHouseContainer
+const defaultClickHouseRetryQueueSize = 16
+
+// ErrClickHouseRetryQueueFull the retry queue of ClickHouseContainer is full, the oldest failed blocks are dropped
+var ErrClickHouseRetryQueueFull = errors.New("clickhouse retry queue is full")
+
+// clickHouseBlock a block of rows to insert, seq is kept so a block sent again reuses its deduplication token
+type clickHouseBlock struct {
+	input proto.Input
+	rows  int
+	seq   uint64
+}
+
+// ClickHouseOption optional setting of ClickHouseContainer
+type ClickHouseOption func(options *clickHouseOptions)
+
+type clickHouseOptions struct {
+	schemaValidationCtx context.Context // validate schema on creation if it's not nil
+	settings            []ch.Setting    // settings of every insert
+	deduplicate         bool            // set insert_deduplication_token of every insert
+	deduplicationPrefix string          // prefix of insert_deduplication_token
+	queryIDPrefix       string          // prefix of query id, empty means random query id
+	retryQueueSize      int             // max number of failed blocks to hold
+}
+
+// WithRetryQueue set the max number of failed blocks held by ClickHouseContainer, default is 16.
+// failed blocks are sent again on subsequent flushes ahead of new data, the oldest ones are dropped when the queue overflows,
+// size 0 disables the queue so a failed block is dropped immediately
+//
+//	@param size int
+//	@return ClickHouseOption
+//	@author kevineluo
+//	@update 2026-10-18 19:14:36
+func WithRetryQueue(size int) ClickHouseOption {
+	return func(options *clickHouseOptions) {
+		if size < 0 {
+			size = 0
+		}
+		options.retryQueueSize = size
+	}
+}
+
+// WithSchemaValidation query system.columns for the target table on creation, and compare it with the columns of
+// the input produced by newInputFunc, so NewClickHouseContainer returns a *ClickHouseSchemaError before any data is buffered
+//
+//	@param ctx context.Context used by the query
+//	@return ClickHouseOption
+//	@author kevineluo
+//	@update 2026-10-18 18:31:09
+func WithSchemaValidation(ctx context.Context) ClickHouseOption {
+	return func(options *clickHouseOptions) {
+		options.schemaValidationCtx = ctx
+	}
+}
+
+// WithInsertSettings add ClickHouse settings to every insert, e.g. ch.SettingInt("max_insert_block_size", 1048576)
+//
+//	@param settings ...ch.Setting
+//	@return ClickHouseOption
+//	@author kevineluo
+//	@update 2026-10-18 18:47:52
+func WithInsertSettings(settings ...ch.Setting) ClickHouseOption {
+	return func(options *clickHouseOptions) {
+		options.settings = append(options.settings, settings...)
+	}
+}
+
+// WithAsyncInsert enable async_insert, the server buffers inserted data and flushes it into table in background.
+// when wait is true, the insert returns after the data is flushed(wait_for_async_insert=1), so failures are reported to Flush
+//
+//	@param wait bool
+//	@return ClickHouseOption
+//	@author kevineluo
+//	@update 2026-10-18 18:47:52
+func WithAsyncInsert(wait bool) ClickHouseOption {
+	waitValue := 0
+	if wait {
+		waitValue = 1
+	}
+	return WithInsertSettings(ch.SettingInt("async_insert", 1), ch.SettingInt("wait_for_async_insert", waitValue))
+}
+
+// WithInsertQuorum set insert_quorum for replicated tables, quorum is the number of replicas or "auto"
+//
+//	@param quorum string
+//	@return ClickHouseOption
+//	@author kevineluo
+//	@update 2026-10-18 18:47:52
+func WithInsertQuorum(quorum string) ClickHouseOption {
+	return WithInsertSettings(ch.Setting{Key: "insert_quorum", Value: quorum})
+}
+
+// WithDeduplicationToken set insert_deduplication_token of every block to "{prefix}-{instance}-{seq}", where instance is a random id
+// generated when the container is created and seq is the sequence number of the block in this container, so a block sent again
+// gets the same token and is deduplicated by server(MergeTree tables need non_replicated_deduplication_window),
+// while blocks of another container or of the same container after a restart never share a token.
+// the token is "{instance}-{seq}" if prefix is empty
+//
+//	@param prefix string
+//	@return ClickHouseOption
+//	@author kevineluo
+//	@update 2026-10-19 14:40:17
+func WithDeduplicationToken(prefix string) ClickHouseOption {
+	return func(options *clickHouseOptions) {
+		options.deduplicate, options.deduplicationPrefix = true, prefix
+	}
 }
 
-func NewClickHouseContainer(pool *chpool.Pool, table string, bulkSize int, newInputFunc func() proto.Input) (*ClickHouseContainer, error) {
+// WithQueryIDPrefix tag every insert with query id "{prefix}-{instance}-{seq}" like the deduplication token,
+// so it can be found in system.query_log
+//
+//	@param prefix string
+//	@return ClickHouseOption
+//	@author kevineluo
+//	@update 2026-10-19 14:40:17
+func WithQueryIDPrefix(prefix string) ClickHouseOption {
+	return func(options *clickHouseOptions) {
+		options.queryIDPrefix = prefix
+	}
+}
+
+// NewClickHousePool new a chpool.Pool with the compression method of inserted blocks,
+// which is negotiated per connection by the native protocol, so it's chosen when creating pool instead of per insert
+//
+//	@param ctx context.Context
+//	@param options chpool.Options
+//	@param compression ch.Compression ch.CompressionLZ4, ch.CompressionZSTD, ch.CompressionNone or ch.CompressionDisabled
+//	@return *chpool.Pool
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 18:47:52
+func NewClickHousePool(ctx context.Context, options chpool.Options, compression ch.Compression) (*chpool.Pool, error) {
+	options.ClientOptions.Compression = compression
+	return chpool.New(ctx, options)
+}
+
+func NewClickHouseContainer(pool *chpool.Pool, table string, bulkSize int, newInputFunc func() proto.Input, opts ...ClickHouseOption) (*ClickHouseContainer, error) {
+	options := clickHouseOptions{retryQueueSize: defaultClickHouseRetryQueueSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.schemaValidationCtx != nil {
+		tableColumns, err := QueryClickHouseColumns(options.schemaValidationCtx, pool, table)
+		if err != nil {
+			return nil, err
+		}
+		if schemaErr := DiffClickHouseSchema(table, inputColumns(newInputFunc()), tableColumns); schemaErr != nil {
+			return nil, fmt.Errorf("[NewClickHouseContainer] %w", schemaErr)
+		}
+	}
+
 	return &ClickHouseContainer{
 		pool:     pool,
 		Table:    table,
@@ -33,6 +194,8 @@ func NewClickHouseContainer(pool *chpool.Pool, table string, bulkSize int, newIn
 		cols:     newInputFunc(),
 
 		newInputFunc: newInputFunc,
+		options:      options,
+		instance:     strings.ReplaceAll(uuid.NewString(), "-", ""),
 	}, nil
 }
 
@@ -44,21 +207,41 @@ func (container *ClickHouseContainer) Put(element ClickHouseRow) error {
 	return nil
 }
 
+// Flush implement interface Container, send blocks in the retry queue first, then the current block.
+// a failed block stays in the retry queue with its sequence number, so it's sent again with the same deduplication token
+// on the next Flush, and blocks behind it wait for it to keep the order
+//
+//	@receiver container *ClickHouseContainer
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 12:08:43
 func (container *ClickHouseContainer) Flush() error {
-	if container.size == 0 {
-		return nil
+	if container.size > 0 {
+		container.seq++
+		container.retryQueue = append(container.retryQueue, clickHouseBlock{
+			input: container.cols,
+			rows:  container.size,
+			seq:   container.seq,
+		})
+		container.cols = container.newInputFunc()
+		container.size = 0
 	}
 
-	dataToWrite := container.cols
-	container.cols = container.newInputFunc()
-	container.size = 0
-
-	// TODO 异步插入如何确保失败时重试、恢复？
-	if err := container.pool.Do(context.TODO(), ch.Query{
-		Body:  dataToWrite.Into(container.Table),
-		Input: dataToWrite,
-	}); err != nil {
-		return err
+	for len(container.retryQueue) > 0 {
+		block := container.retryQueue[0]
+		if err := container.pool.Do(context.TODO(), container.InsertQuery(block.input, block.seq)); err != nil {
+			if dropped := len(container.retryQueue) - container.options.retryQueueSize; dropped > 0 {
+				rows := 0
+				for _, block := range container.retryQueue[:dropped] {
+					rows += block.rows
+				}
+				container.retryQueue = container.retryQueue[dropped:]
+				return &KeptError{Dropped: rows, Err: fmt.Errorf("[ClickHouseContainer.Flush] %w, dropped %d blocks: %w", ErrClickHouseRetryQueueFull, dropped, err)}
+			}
+			return &KeptError{Err: fmt.Errorf("[ClickHouseContainer.Flush] %d blocks wait for retry: %w", len(container.retryQueue), err)}
+		}
+		container.retryQueue[0] = clickHouseBlock{}
+		container.retryQueue = container.retryQueue[1:]
 	}
 
 	return nil
@@ -71,8 +254,80 @@ func (container *ClickHouseContainer) IsFull() bool {
 	return false
 }
 
+// Reset implement interface Container, drop rows of the current block, failed blocks in the retry queue are kept
+//
+//	@receiver container *ClickHouseContainer
+//	@author kevineluo
+//	@update 2026-10-18 19:14:36
 func (container *ClickHouseContainer) Reset() {
-	// TODO 如何处理cols中残留数据?
 	container.size = 0
 	container.cols.Reset()
 }
+
+// KeepFailed implement interface Keeper, failed blocks are kept in the retry queue
+//
+//	@receiver container *ClickHouseContainer
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *ClickHouseContainer) KeepFailed() {}
+
+// Len return the number of rows in the current block
+//
+//	@receiver container *ClickHouseContainer
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 19:14:36
+func (container *ClickHouseContainer) Len() int {
+	return container.size
+}
+
+// RetryQueueLen return the number of failed blocks waiting to be sent again
+//
+//	@receiver container *ClickHouseContainer
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 19:14:36
+func (container *ClickHouseContainer) RetryQueueLen() int {
+	return len(container.retryQueue)
+}
+
+// InsertQuery build the insert query of a block with settings, deduplication token and query id, which is sent by Flush
+//
+//	@receiver container *ClickHouseContainer
+//	@param input proto.Input
+//	@param seq uint64 sequence number of the block
+//	@return ch.Query
+//	@author kevineluo
+//	@update 2026-10-19 14:40:17
+func (container *ClickHouseContainer) InsertQuery(input proto.Input, seq uint64) ch.Query {
+	query := ch.Query{
+		Body:     input.Into(container.Table),
+		Input:    input,
+		Settings: append([]ch.Setting{}, container.options.settings...),
+	}
+	if container.options.deduplicate {
+		query.Settings = append(query.Settings, ch.Setting{
+			Key:   "insert_deduplication_token",
+			Value: container.blockID(container.options.deduplicationPrefix, seq),
+		})
+		for _, setting := range container.options.settings {
+			if setting.Key == "async_insert" && setting.Value == "1" {
+				// async inserts are not deduplicated by default
+				query.Settings = append(query.Settings, ch.SettingInt("async_insert_deduplicate", 1))
+				break
+			}
+		}
+	}
+	if container.options.queryIDPrefix != "" {
+		query.QueryID = container.blockID(container.options.queryIDPrefix, seq)
+	}
+	return query
+}
+
+// blockID identify the seq-th block of the container as "{prefix}-{instance}-{seq}", or "{instance}-{seq}" without prefix
+func (container *ClickHouseContainer) blockID(prefix string, seq uint64) string {
+	if prefix == "" {
+		return fmt.Sprintf("%s-%d", container.instance, seq)
+	}
+	return fmt.Sprintf("%s-%s-%d", prefix, container.instance, seq)
+}
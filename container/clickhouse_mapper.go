@@ -0,0 +1,411 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/ch-go/chpool"
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/google/uuid"
+)
+
+var (
+	_ Container[int] = &ClickHouseMappedContainer[int]{}
+	_ Keeper         = &ClickHouseMappedContainer[int]{}
+
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// clickHouseField map a struct field into a column
+type clickHouseField struct {
+	name        string
+	index       int
+	newColumn   func() proto.ColInput
+	appendValue func(column proto.ColInput, value reflect.Value)
+}
+
+// ClickHouseMapper build proto.Input from `ch` struct tags of T, so row types don't have to implement ClickHouseRow by hand.
+// the tag format is `ch:"name[,lowcardinality][,datetime64=precision]"`, name defaults to field name,
+// fields tagged with "-" and unexported fields are skipped.
+//
+// supported field types and their columns:
+//
+//   - string: String
+//
+//   - int8-int64, int: Int8-Int64, Int64
+//
+//   - uint8-uint64, uint: UInt8-UInt64, UInt64
+//
+//   - float32, float64: Float32, Float64
+//
+//   - bool: Bool
+//
+//   - time.Time: DateTime, or DateTime64(precision) with option datetime64
+//
+//   - uuid.UUID: UUID
+//
+//   - *X: Nullable(X)
+//
+//   - []X: Array(X)
+//
+//   - option lowcardinality wraps X as LowCardinality(X), e.g. []string with lowcardinality is Array(LowCardinality(String))
+//
+//     @author kevineluo
+//     @update 2026-10-18 17:52:26
+type ClickHouseMapper[T any] struct {
+	fields []clickHouseField
+}
+
+// NewClickHouseMapper new a ClickHouseMapper by parsing struct tags of T
+//
+//	@return *ClickHouseMapper[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func NewClickHouseMapper[T any]() (*ClickHouseMapper[T], error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("[NewClickHouseMapper] unsupported type %v, T should be a struct", typ)
+	}
+
+	mapper := &ClickHouseMapper[T]{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("ch")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		lowCardinality, precision := false, -1
+		for _, option := range strings.Split(options, ",") {
+			switch {
+			case option == "":
+			case option == "lowcardinality":
+				lowCardinality = true
+			case strings.HasPrefix(option, "datetime64="):
+				p, err := strconv.Atoi(strings.TrimPrefix(option, "datetime64="))
+				if err != nil || p < 0 || p > 9 {
+					return nil, fmt.Errorf("[NewClickHouseMapper] invalid option %s of field %s, precision should be in [0, 9]", option, field.Name)
+				}
+				precision = p
+			default:
+				return nil, fmt.Errorf("[NewClickHouseMapper] unsupported option %s of field %s", option, field.Name)
+			}
+		}
+
+		newColumn, appendValue, err := clickHouseColumnOf(field.Type, lowCardinality, precision)
+		if err != nil {
+			return nil, fmt.Errorf("[NewClickHouseMapper] field %s: %w", field.Name, err)
+		}
+		mapper.fields = append(mapper.fields, clickHouseField{name: name, index: i, newColumn: newColumn, appendValue: appendValue})
+	}
+	if len(mapper.fields) == 0 {
+		return nil, fmt.Errorf("[NewClickHouseMapper] type %v has no column", typ)
+	}
+	return mapper, nil
+}
+
+// NewInput new an empty proto.Input holding all mapped columns, it can be used as newInputFunc of ClickHouseContainer
+//
+//	@receiver mapper *ClickHouseMapper[T]
+//	@return proto.Input
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func (mapper *ClickHouseMapper[T]) NewInput() proto.Input {
+	input := make(proto.Input, 0, len(mapper.fields))
+	for _, field := range mapper.fields {
+		input = append(input, proto.InputColumn{Name: field.name, Data: field.newColumn()})
+	}
+	return input
+}
+
+// Append append a row into input created by NewInput
+//
+//	@receiver mapper *ClickHouseMapper[T]
+//	@param input proto.Input
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func (mapper *ClickHouseMapper[T]) Append(input proto.Input, element T) error {
+	if len(input) != len(mapper.fields) {
+		return fmt.Errorf("[ClickHouseMapper.Append] input has %d columns, but %d are mapped, input should be created by NewInput", len(input), len(mapper.fields))
+	}
+	value := reflect.ValueOf(element)
+	for i, field := range mapper.fields {
+		field.appendValue(input[i].Data, value.Field(field.index))
+	}
+	return nil
+}
+
+// Row wrap an element as ClickHouseRow, so it can be put into ClickHouseContainer created with NewInput
+//
+//	@receiver mapper *ClickHouseMapper[T]
+//	@param element T
+//	@return ClickHouseRow
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func (mapper *ClickHouseMapper[T]) Row(element T) ClickHouseRow {
+	return clickHouseMappedRow[T]{mapper: mapper, element: element}
+}
+
+// Columns return mapped columns with their types
+//
+//	@receiver mapper *ClickHouseMapper[T]
+//	@return []ClickHouseColumn
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func (mapper *ClickHouseMapper[T]) Columns() []ClickHouseColumn {
+	columns := make([]ClickHouseColumn, 0, len(mapper.fields))
+	for _, field := range mapper.fields {
+		columns = append(columns, ClickHouseColumn{Name: field.name, Type: string(field.newColumn().Type())})
+	}
+	return columns
+}
+
+// Validate check mapped columns against the table schema, which can be got by QueryClickHouseColumns:
+// every mapped column should exist in table with the same type and be insertable(not MATERIALIZED or ALIAS).
+// a *ClickHouseSchemaError would be returned if they don't match
+//
+//	@receiver mapper *ClickHouseMapper[T]
+//	@param tableColumns []ClickHouseColumn
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 18:31:09
+func (mapper *ClickHouseMapper[T]) Validate(tableColumns []ClickHouseColumn) error {
+	if schemaErr := DiffClickHouseSchema("", mapper.Columns(), tableColumns); schemaErr != nil {
+		return schemaErr
+	}
+	return nil
+}
+
+// clickHouseMappedRow implement ClickHouseRow with ClickHouseMapper
+type clickHouseMappedRow[T any] struct {
+	mapper  *ClickHouseMapper[T]
+	element T
+}
+
+// Insert implement interface ClickHouseRow
+func (row clickHouseMappedRow[T]) Insert(input proto.Input) error {
+	return row.mapper.Append(input, row.element)
+}
+
+// ClickHouseMappedContainer a ClickHouseContainer accepting T directly, columns are mapped by ClickHouseMapper[T].
+// not thread safe
+//
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+type ClickHouseMappedContainer[T any] struct {
+	*ClickHouseMapper[T]
+	container *ClickHouseContainer
+}
+
+// NewClickHouseMappedContainer new a ClickHouseMappedContainer, the mapped columns would be validated against
+// the table schema from system.columns, so mismatches are found before any data is buffered.
+// opts are passed to NewClickHouseContainer after WithSchemaValidation(ctx), so WithSchemaValidation(nil) skips the validation
+//
+//	@param ctx context.Context used by the schema validation
+//	@param pool *chpool.Pool
+//	@param table string
+//	@param bulkSize int
+//	@param opts ...ClickHouseOption
+//	@return *ClickHouseMappedContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 15:41:06
+func NewClickHouseMappedContainer[T any](ctx context.Context, pool *chpool.Pool, table string, bulkSize int, opts ...ClickHouseOption) (*ClickHouseMappedContainer[T], error) {
+	mapper, err := NewClickHouseMapper[T]()
+	if err != nil {
+		return nil, err
+	}
+	container, err := NewClickHouseContainer(pool, table, bulkSize, mapper.NewInput, append([]ClickHouseOption{WithSchemaValidation(ctx)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClickHouseMappedContainer[T]{ClickHouseMapper: mapper, container: container}, nil
+}
+
+// Put implement interface Container
+//
+//	@receiver container *ClickHouseMappedContainer[T]
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func (container *ClickHouseMappedContainer[T]) Put(element T) error {
+	return container.container.Put(container.Row(element))
+}
+
+// Flush implement interface Container
+//
+//	@receiver container *ClickHouseMappedContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func (container *ClickHouseMappedContainer[T]) Flush() error {
+	return container.container.Flush()
+}
+
+// IsFull implement interface Container
+//
+//	@receiver container *ClickHouseMappedContainer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func (container *ClickHouseMappedContainer[T]) IsFull() bool {
+	return container.container.IsFull()
+}
+
+// Reset implement interface Container
+//
+//	@receiver container *ClickHouseMappedContainer[T]
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func (container *ClickHouseMappedContainer[T]) Reset() {
+	container.container.Reset()
+}
+
+// KeepFailed implement interface Keeper, failed blocks are kept in the retry queue of ClickHouseContainer
+//
+//	@receiver container *ClickHouseMappedContainer[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *ClickHouseMappedContainer[T]) KeepFailed() {}
+
+// Len return the number of rows in the current block
+//
+//	@receiver container *ClickHouseMappedContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-19 15:41:06
+func (container *ClickHouseMappedContainer[T]) Len() int {
+	return container.container.Len()
+}
+
+// RetryQueueLen return the number of failed blocks waiting to be sent again
+//
+//	@receiver container *ClickHouseMappedContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-19 15:41:06
+func (container *ClickHouseMappedContainer[T]) RetryQueueLen() int {
+	return container.container.RetryQueueLen()
+}
+
+// clickHouseColumnOf build the column factory and appender of a field type
+func clickHouseColumnOf(typ reflect.Type, lowCardinality bool, precision int) (func() proto.ColInput, func(proto.ColInput, reflect.Value), error) {
+	elemType := typ
+	if typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+		elemType = typ.Elem()
+	}
+	if precision >= 0 && elemType != timeType {
+		return nil, nil, errors.New("option datetime64 is only supported by time.Time")
+	}
+
+	switch elemType {
+	case timeType:
+		if precision >= 0 {
+			return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[time.Time] {
+				return new(proto.ColDateTime64).WithPrecision(proto.Precision(precision))
+			}, func(value reflect.Value) time.Time { return value.Interface().(time.Time) })
+		}
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[time.Time] { return new(proto.ColDateTime) },
+			func(value reflect.Value) time.Time { return value.Interface().(time.Time) })
+	case uuidType:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[uuid.UUID] { return new(proto.ColUUID) },
+			func(value reflect.Value) uuid.UUID { return value.Interface().(uuid.UUID) })
+	}
+
+	switch elemType.Kind() {
+	case reflect.String:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[string] { return new(proto.ColStr) }, reflect.Value.String)
+	case reflect.Bool:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[bool] { return new(proto.ColBool) }, reflect.Value.Bool)
+	case reflect.Int8:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[int8] { return new(proto.ColInt8) },
+			func(value reflect.Value) int8 { return int8(value.Int()) })
+	case reflect.Int16:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[int16] { return new(proto.ColInt16) },
+			func(value reflect.Value) int16 { return int16(value.Int()) })
+	case reflect.Int32:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[int32] { return new(proto.ColInt32) },
+			func(value reflect.Value) int32 { return int32(value.Int()) })
+	case reflect.Int64, reflect.Int:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[int64] { return new(proto.ColInt64) }, reflect.Value.Int)
+	case reflect.Uint8:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[uint8] { return new(proto.ColUInt8) },
+			func(value reflect.Value) uint8 { return uint8(value.Uint()) })
+	case reflect.Uint16:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[uint16] { return new(proto.ColUInt16) },
+			func(value reflect.Value) uint16 { return uint16(value.Uint()) })
+	case reflect.Uint32:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[uint32] { return new(proto.ColUInt32) },
+			func(value reflect.Value) uint32 { return uint32(value.Uint()) })
+	case reflect.Uint64, reflect.Uint:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[uint64] { return new(proto.ColUInt64) }, reflect.Value.Uint)
+	case reflect.Float32:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[float32] { return new(proto.ColFloat32) },
+			func(value reflect.Value) float32 { return float32(value.Float()) })
+	case reflect.Float64:
+		return clickHouseColumn(typ, lowCardinality, func() proto.ColumnOf[float64] { return new(proto.ColFloat64) }, reflect.Value.Float)
+	}
+	return nil, nil, fmt.Errorf("unsupported type %v", typ)
+}
+
+// clickHouseColumn build the column factory and appender of V, *V(Nullable) or []V(Array)
+//
+//	@param typ reflect.Type field type
+//	@param lowCardinality bool wrap V as LowCardinality(V)
+//	@param newValues func() proto.ColumnOf[V] new the column of V
+//	@param convert func(reflect.Value) V convert the field value into V
+//	@return func() proto.ColInput
+//	@return func(proto.ColInput, reflect.Value)
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func clickHouseColumn[V comparable](typ reflect.Type, lowCardinality bool, newValues func() proto.ColumnOf[V], convert func(reflect.Value) V) (func() proto.ColInput, func(proto.ColInput, reflect.Value), error) {
+	if lowCardinality {
+		newBase := newValues
+		newValues = func() proto.ColumnOf[V] { return proto.NewLowCardinality(newBase()) }
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		if lowCardinality {
+			return nil, nil, errors.New("option lowcardinality is not supported by Nullable")
+		}
+		return func() proto.ColInput { return proto.NewColNullable(newValues()) },
+			func(column proto.ColInput, value reflect.Value) {
+				if value.IsNil() {
+					column.(*proto.ColNullable[V]).Append(proto.Null[V]())
+					return
+				}
+				column.(*proto.ColNullable[V]).Append(proto.NewNullable(convert(value.Elem())))
+			}, nil
+	case reflect.Slice:
+		return func() proto.ColInput { return proto.NewArray(newValues()) },
+			func(column proto.ColInput, value reflect.Value) {
+				values := make([]V, value.Len())
+				for i := range values {
+					values[i] = convert(value.Index(i))
+				}
+				column.(*proto.ColArr[V]).Append(values)
+			}, nil
+	default:
+		return func() proto.ColInput { return newValues() },
+			func(column proto.ColInput, value reflect.Value) {
+				column.(proto.ColumnOf[V]).Append(convert(value))
+			}, nil
+	}
+}
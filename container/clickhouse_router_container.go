@@ -0,0 +1,229 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ClickHouse/ch-go/chpool"
+	"github.com/ClickHouse/ch-go/proto"
+)
+
+var (
+	_ Container[ClickHouseRoutedRow] = &ClickHouseRouterContainer{}
+	_ Keeper                         = &ClickHouseRouterContainer{}
+)
+
+// ClickHouseRoutedRow a row which decides its target table
+type ClickHouseRoutedRow interface {
+	ClickHouseRow
+	Table() string
+}
+
+// ClickHouseRouterError tables which fail to insert in a Flush, the others have been inserted successfully
+//
+//	@author kevineluo
+//	@update 2026-10-18 19:02:14
+type ClickHouseRouterError struct {
+	Failed map[string]error // insert error of every failed table
+}
+
+// Error implement interface error
+//
+//	@receiver err *ClickHouseRouterError
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-18 19:02:14
+func (err *ClickHouseRouterError) Error() string {
+	tables := err.Tables()
+	messages := make([]string, 0, len(tables))
+	for _, table := range tables {
+		messages = append(messages, fmt.Sprintf("%s: %v", table, err.Failed[table]))
+	}
+	return fmt.Sprintf("%d tables failed to insert: %s", len(tables), strings.Join(messages, "; "))
+}
+
+// Unwrap return errors of failed tables, so errors.Is and errors.As can inspect them
+//
+//	@receiver err *ClickHouseRouterError
+//	@return []error
+//	@author kevineluo
+//	@update 2026-10-18 19:02:14
+func (err *ClickHouseRouterError) Unwrap() []error {
+	errs := make([]error, 0, len(err.Failed))
+	for _, table := range err.Tables() {
+		errs = append(errs, err.Failed[table])
+	}
+	return errs
+}
+
+// Tables return the sorted names of failed tables
+//
+//	@receiver err *ClickHouseRouterError
+//	@return []string
+//	@author kevineluo
+//	@update 2026-10-18 19:02:14
+func (err *ClickHouseRouterError) Tables() []string {
+	tables := make([]string, 0, len(err.Failed))
+	for table := range err.Failed {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// ClickHouseRouterContainer route rows into multiple tables by ClickHouseRoutedRow.Table, every table holds its own block
+// in a ClickHouseContainer, so options like deduplication token apply to every table separately.
+// Flush inserts the block of every table, failed tables are reported by *ClickHouseRouterError,
+// while the blocks of successful tables are done and would not be sent again,
+// blocks of failed tables wait in the retry queue of their ClickHouseContainer.
+// not thread safe
+//
+//	@author kevineluo
+//	@update 2026-10-18 19:02:14
+type ClickHouseRouterContainer struct {
+	tables     []string // sorted table names
+	containers map[string]*ClickHouseContainer
+}
+
+// NewClickHouseRouterContainer new a ClickHouseRouterContainer
+//
+//	@param pool *chpool.Pool
+//	@param bulkSize int the container would be full when any table holds bulkSize rows
+//	@param newInputFuncs map[string]func() proto.Input input factory of every target table
+//	@param opts ...ClickHouseOption applied to every table, see NewClickHouseContainer
+//	@return *ClickHouseRouterContainer
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 19:02:14
+func NewClickHouseRouterContainer(pool *chpool.Pool, bulkSize int, newInputFuncs map[string]func() proto.Input, opts ...ClickHouseOption) (*ClickHouseRouterContainer, error) {
+	if len(newInputFuncs) == 0 {
+		return nil, fmt.Errorf("[NewClickHouseRouterContainer] newInputFuncs should not be empty")
+	}
+	router := &ClickHouseRouterContainer{containers: make(map[string]*ClickHouseContainer, len(newInputFuncs))}
+	for table, newInputFunc := range newInputFuncs {
+		container, err := NewClickHouseContainer(pool, table, bulkSize, newInputFunc, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("[NewClickHouseRouterContainer] table %s: %w", table, err)
+		}
+		router.tables = append(router.tables, table)
+		router.containers[table] = container
+	}
+	sort.Strings(router.tables)
+	return router, nil
+}
+
+// Put implement interface Container, append the row into the block of its table
+//
+//	@receiver router *ClickHouseRouterContainer
+//	@param element ClickHouseRoutedRow
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 19:02:14
+func (router *ClickHouseRouterContainer) Put(element ClickHouseRoutedRow) error {
+	container, ok := router.containers[element.Table()]
+	if !ok {
+		return fmt.Errorf("[ClickHouseRouterContainer.Put] unknown table: %s", element.Table())
+	}
+	return container.Put(element)
+}
+
+// Flush implement interface Container, insert the block of every table.
+// failed blocks are kept by tables, the returned *KeptError wraps *ClickHouseRouterError and counts rows dropped by all tables
+//
+//	@receiver router *ClickHouseRouterContainer
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 12:08:43
+func (router *ClickHouseRouterContainer) Flush() error {
+	routerErr := &ClickHouseRouterError{Failed: make(map[string]error)}
+	dropped := 0
+	for _, table := range router.tables {
+		if err := router.containers[table].Flush(); err != nil {
+			routerErr.Failed[table] = err
+			var keptErr *KeptError
+			if errors.As(err, &keptErr) {
+				dropped += keptErr.Dropped
+			}
+		}
+	}
+	if len(routerErr.Failed) > 0 {
+		return &KeptError{Dropped: dropped, Err: routerErr}
+	}
+	return nil
+}
+
+// IsFull implement interface Container, return true if any table is full
+//
+//	@receiver router *ClickHouseRouterContainer
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 19:02:14
+func (router *ClickHouseRouterContainer) IsFull() bool {
+	for _, container := range router.containers {
+		if container.IsFull() {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset implement interface Container
+//
+//	@receiver router *ClickHouseRouterContainer
+//	@author kevineluo
+//	@update 2026-10-18 19:02:14
+func (router *ClickHouseRouterContainer) Reset() {
+	for _, container := range router.containers {
+		container.Reset()
+	}
+}
+
+// KeepFailed implement interface Keeper, failed blocks are kept in the retry queues of tables
+//
+//	@receiver router *ClickHouseRouterContainer
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (router *ClickHouseRouterContainer) KeepFailed() {}
+
+// Len return the number of pending rows of all tables, so Buffer can report it in Stats and metrics
+//
+//	@receiver router *ClickHouseRouterContainer
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-19 13:15:27
+func (router *ClickHouseRouterContainer) Len() (rows int) {
+	for _, container := range router.containers {
+		rows += container.Len()
+	}
+	return
+}
+
+// TableLen return the number of pending rows of table
+//
+//	@receiver router *ClickHouseRouterContainer
+//	@param table string
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-19 13:15:27
+func (router *ClickHouseRouterContainer) TableLen(table string) int {
+	if container, ok := router.containers[table]; ok {
+		return container.Len()
+	}
+	return 0
+}
+
+// RetryQueueLen return the number of failed blocks of table waiting to be sent again
+//
+//	@receiver router *ClickHouseRouterContainer
+//	@param table string
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 19:14:36
+func (router *ClickHouseRouterContainer) RetryQueueLen(table string) int {
+	if container, ok := router.containers[table]; ok {
+		return container.RetryQueueLen()
+	}
+	return 0
+}
@@ -0,0 +1,166 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ClickHouse/ch-go"
+	"github.com/ClickHouse/ch-go/chpool"
+	"github.com/ClickHouse/ch-go/proto"
+)
+
+var (
+	clickHouseDateTime64Pattern = regexp.MustCompile(`DateTime64\((\d+)(,'[^']*')?\)`)
+	clickHouseDateTimePattern   = regexp.MustCompile(`DateTime\('[^']*'\)`)
+)
+
+// ClickHouseColumn a column of ClickHouse table
+//
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+type ClickHouseColumn struct {
+	Name        string // column name
+	Type        string // column type, e.g. "LowCardinality(String)"
+	DefaultKind string // "", "DEFAULT", "MATERIALIZED", "ALIAS" or "EPHEMERAL"
+}
+
+// ClickHouseColumnDiff a column whose type differs between table and input
+//
+//	@author kevineluo
+//	@update 2026-10-18 18:31:09
+type ClickHouseColumnDiff struct {
+	Name      string // column name
+	TableType string // type in table
+	InputType string // type of the column in input
+}
+
+// ClickHouseSchemaError the detailed difference between input columns and table schema
+//
+//	@author kevineluo
+//	@update 2026-10-18 18:31:09
+type ClickHouseSchemaError struct {
+	Table         string                 // target table
+	Missing       []string               // input columns which don't exist in table
+	NotInsertable []string               // input columns which are MATERIALIZED or ALIAS in table
+	Mismatched    []ClickHouseColumnDiff // input columns whose type differs from table
+}
+
+// Error implement interface error
+//
+//	@receiver err *ClickHouseSchemaError
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-18 18:31:09
+func (err *ClickHouseSchemaError) Error() string {
+	var builder strings.Builder
+	if err.Table == "" {
+		builder.WriteString("input doesn't match the table schema:")
+	} else {
+		fmt.Fprintf(&builder, "input doesn't match the schema of table %s:", err.Table)
+	}
+	for _, name := range err.Missing {
+		fmt.Fprintf(&builder, "\n\t- column %s doesn't exist in table", name)
+	}
+	for _, name := range err.NotInsertable {
+		fmt.Fprintf(&builder, "\n\t- column %s is MATERIALIZED or ALIAS and can't be inserted", name)
+	}
+	for _, diff := range err.Mismatched {
+		fmt.Fprintf(&builder, "\n\t- column %s is %s in table, but %s in input", diff.Name, diff.TableType, diff.InputType)
+	}
+	return builder.String()
+}
+
+// inputColumns return columns of input with their types
+func inputColumns(input proto.Input) []ClickHouseColumn {
+	columns := make([]ClickHouseColumn, 0, len(input))
+	for _, column := range input {
+		columns = append(columns, ClickHouseColumn{Name: column.Name, Type: string(column.Data.Type())})
+	}
+	return columns
+}
+
+// DiffClickHouseSchema compare input columns with table columns, e.g. ClickHouseMapper.Columns with the result of QueryClickHouseColumns:
+// every input column should exist in table with the same type(timezones of DateTime and DateTime64 are ignored) and be insertable,
+// table columns absent from input are ignored since ClickHouse fills them with default value
+//
+//	@param table string
+//	@param columns []ClickHouseColumn input columns
+//	@param tableColumns []ClickHouseColumn
+//	@return *ClickHouseSchemaError nil if they match
+//	@author kevineluo
+//	@update 2026-10-19 16:04:37
+func DiffClickHouseSchema(table string, columns []ClickHouseColumn, tableColumns []ClickHouseColumn) *ClickHouseSchemaError {
+	tableColumnMap := make(map[string]ClickHouseColumn, len(tableColumns))
+	for _, column := range tableColumns {
+		tableColumnMap[column.Name] = column
+	}
+	schemaErr := &ClickHouseSchemaError{Table: table}
+	for _, column := range columns {
+		tableColumn, ok := tableColumnMap[column.Name]
+		switch {
+		case !ok:
+			schemaErr.Missing = append(schemaErr.Missing, column.Name)
+		case tableColumn.DefaultKind == "MATERIALIZED" || tableColumn.DefaultKind == "ALIAS":
+			schemaErr.NotInsertable = append(schemaErr.NotInsertable, column.Name)
+		case normalizeClickHouseType(tableColumn.Type) != normalizeClickHouseType(column.Type):
+			schemaErr.Mismatched = append(schemaErr.Mismatched, ClickHouseColumnDiff{Name: column.Name, TableType: tableColumn.Type, InputType: column.Type})
+		}
+	}
+	if len(schemaErr.Missing)+len(schemaErr.NotInsertable)+len(schemaErr.Mismatched) == 0 {
+		return nil
+	}
+	return schemaErr
+}
+
+// QueryClickHouseColumns query columns of table from system.columns, table can be "table" or "database.table"
+//
+//	@param ctx context.Context
+//	@param pool *chpool.Pool
+//	@param table string
+//	@return columns []ClickHouseColumn
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 17:52:26
+func QueryClickHouseColumns(ctx context.Context, pool *chpool.Pool, table string) (columns []ClickHouseColumn, err error) {
+	database := "currentDatabase()"
+	if db, name, ok := strings.Cut(table, "."); ok {
+		database, table = quoteClickHouseString(db), name
+	}
+	var names, types, defaultKinds proto.ColStr
+	err = pool.Do(ctx, ch.Query{
+		Body: fmt.Sprintf("SELECT name, type, default_kind FROM system.columns WHERE database = %s AND table = %s ORDER BY position",
+			database, quoteClickHouseString(table)),
+		Result: proto.Results{
+			{Name: "name", Data: &names},
+			{Name: "type", Data: &types},
+			{Name: "default_kind", Data: &defaultKinds},
+		},
+		OnResult: func(ctx context.Context, block proto.Block) error {
+			for i := 0; i < names.Rows(); i++ {
+				columns = append(columns, ClickHouseColumn{Name: names.Row(i), Type: types.Row(i), DefaultKind: defaultKinds.Row(i)})
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[QueryClickHouseColumns] fail to query columns of %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("[QueryClickHouseColumns] table %s doesn't exist or has no column", table)
+	}
+	return
+}
+
+// normalizeClickHouseType drop the timezone of DateTime and DateTime64, which doesn't affect inserting
+func normalizeClickHouseType(typ string) string {
+	typ = strings.ReplaceAll(typ, " ", "")
+	typ = clickHouseDateTime64Pattern.ReplaceAllString(typ, "DateTime64($1)")
+	return clickHouseDateTimePattern.ReplaceAllString(typ, "DateTime")
+}
+
+// quoteClickHouseString quote s as a ClickHouse string literal
+func quoteClickHouseString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
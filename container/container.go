@@ -14,6 +14,16 @@ type Container[T any] interface {
 	Flush() error
 	// IsFull return true if this container is full
 	IsFull() bool
-	// will call Reset when flush return error
+	// will call Reset when flush return error, unless the container implements Keeper
 	Reset()
 }
+
+// Keeper optional interface of Container which takes care of data failed in Flush by itself: it keeps them to be flushed again
+// by next Flush, reported by *KeptError or FailedElement.Requeued of *PartialFlushError, and drops the others.
+// KeepFailed is only a marker, Buffer won't call Reset after a failed Flush of a container implementing it
+//
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+type Keeper interface {
+	KeepFailed()
+}
@@ -0,0 +1,357 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	_ Container[int] = &ElasticsearchBulkContainer[int]{}
+	_ Keeper         = &ElasticsearchBulkContainer[int]{}
+)
+
+// ElasticsearchBulkConfig ElasticsearchBulkContainer Config
+//
+//	@author kevineluo
+//	@update 2026-10-18 14:22:37
+type ElasticsearchBulkConfig[T any] struct {
+	ID        string // used as {buffer_id} in IndexTemplate
+	FlushSize int    // container would be full when holding FlushSize documents
+	URL       string // base url of Elasticsearch/OpenSearch, e.g. "http://localhost:9200", the request would be sent to URL/_bulk
+	Action    string // bulk action, "index"(default) or "create"
+	// IndexTemplate target index, support placeholders: {date}, {hour}, {minute}, {timestamp}(flush time in UTC) and {buffer_id}.
+	// e.g. "events-{date}"
+	IndexTemplate string
+	Index         func(T) string // optional, decide the target index of a document, IndexTemplate would be ignored when it's set
+	DocumentID    func(T) string // optional, decide the _id of a document, empty _id means auto generated
+	Header        http.Header    // extra headers of the bulk request, e.g. Authorization
+	Timeout       time.Duration  // timeout of the bulk request, default is 30s
+	// RetryableStatus item status which would be requeued and retried by next Flush, default is 429, 502, 503 and 504
+	RetryableStatus []int
+	MaxAttempts     int          // max attempts of a document, it would be reported as permanently failed after that, default is 3
+	HTTPClient      *http.Client // default is http.DefaultClient
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *ElasticsearchBulkConfig[T]
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 14:22:37
+func (config *ElasticsearchBulkConfig[T]) Validate() (err error) {
+	if config.FlushSize <= 0 {
+		return fmt.Errorf("[ElasticsearchBulkConfig.Validate] invalid FlushSize: %d, FlushSize should be positive", config.FlushSize)
+	}
+	if config.URL == "" {
+		return errors.New("[ElasticsearchBulkConfig.Validate] URL should not be empty")
+	}
+	if config.IndexTemplate == "" && config.Index == nil {
+		return errors.New("[ElasticsearchBulkConfig.Validate] one of IndexTemplate and Index should be set")
+	}
+	switch config.Action {
+	case "":
+		config.Action = "index"
+	case "index", "create":
+	default:
+		return fmt.Errorf("[ElasticsearchBulkConfig.Validate] unsupported action: %s, action should be index or create", config.Action)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.RetryableStatus == nil {
+		config.RetryableStatus = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return
+}
+
+// ElasticsearchItemError the error of a bulk item
+//
+//	@author kevineluo
+//	@update 2026-10-18 14:22:37
+type ElasticsearchItemError struct {
+	Status int    // item status
+	Type   string // error type, e.g. "mapper_parsing_exception"
+	Reason string // error reason
+}
+
+// Error implement interface error
+func (err *ElasticsearchItemError) Error() string {
+	return fmt.Sprintf("bulk item failed with status %d: [%s] %s", err.Status, err.Type, err.Reason)
+}
+
+// elasticsearchDocument a pending document and its attempts
+type elasticsearchDocument[T any] struct {
+	element  T
+	attempts int
+}
+
+// bulkResponse response of the _bulk API
+type bulkResponse struct {
+	Errors bool                        `json:"errors"`
+	Items  []map[string]bulkItemResult `json:"items"`
+}
+
+type bulkItemResult struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// ElasticsearchBulkContainer flush every batch as one `_bulk` request, items failed with retryable status would be requeued
+// and sent again by next Flush, the others would be reported as permanently failed by *PartialFlushError[T].
+// when the whole request fails with network error or retryable status, every document is requeued the same way,
+// so a document is dropped after MaxAttempts even if the cluster is down. a 2xx response which can't be decoded fails the flush
+// without requeuing, since the documents may have been indexed.
+// Put can be called while an async Flush is running, flushes run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 15:03:27
+type ElasticsearchBulkContainer[T any] struct {
+	config ElasticsearchBulkConfig[T]
+	batch  batch[elasticsearchDocument[T]]
+}
+
+// NewElasticsearchBulkContainer new an ElasticsearchBulkContainer
+//
+//	@param config ElasticsearchBulkConfig[T]
+//	@return *ElasticsearchBulkContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 14:22:37
+func NewElasticsearchBulkContainer[T any](config ElasticsearchBulkConfig[T]) (*ElasticsearchBulkContainer[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &ElasticsearchBulkContainer[T]{
+		config: config,
+		batch:  newBatch[elasticsearchDocument[T]](config.FlushSize),
+	}, nil
+}
+
+// Put implement interface Container
+//
+//	@receiver container *ElasticsearchBulkContainer[T]
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 14:22:37
+func (container *ElasticsearchBulkContainer[T]) Put(element T) error {
+	container.batch.put(elasticsearchDocument[T]{element: element})
+	return nil
+}
+
+// Flush implement interface Container
+//
+//	@receiver container *ElasticsearchBulkContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 15:03:27
+func (container *ElasticsearchBulkContainer[T]) Flush() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	if container.batch.len() == 0 {
+		return nil
+	}
+	documents := container.batch.take()
+
+	body, err := container.render(documents, time.Now())
+	if err != nil {
+		return fmt.Errorf("[ElasticsearchBulkContainer.Flush] fail to render bulk request: %w", err)
+	}
+
+	response, retryable, err := container.send(body)
+	if err != nil {
+		err = fmt.Errorf("[ElasticsearchBulkContainer.Flush] fail to send bulk request: %w", err)
+		if !retryable {
+			return err
+		}
+		// the whole request may succeed by retrying, documents reaching MaxAttempts are reported as permanently failed
+		partialErr := &PartialFlushError[T]{Total: len(documents)}
+		requeue := make([]elasticsearchDocument[T], 0, len(documents))
+		for _, document := range documents {
+			document.attempts++
+			requeued := document.attempts < container.config.MaxAttempts
+			if requeued {
+				requeue = append(requeue, document)
+			}
+			partialErr.Failed = append(partialErr.Failed, FailedElement[T]{Element: document.element, Err: err, Requeued: requeued})
+		}
+		container.batch.restore(requeue)
+		return partialErr
+	}
+	if !response.Errors {
+		return nil
+	}
+	if len(response.Items) != len(documents) {
+		return fmt.Errorf("[ElasticsearchBulkContainer.Flush] bulk response has %d items, but %d documents are sent", len(response.Items), len(documents))
+	}
+
+	partialErr := &PartialFlushError[T]{Total: len(documents)}
+	requeue := make([]elasticsearchDocument[T], 0)
+	for i, item := range response.Items {
+		for _, result := range item {
+			if result.Status < 300 {
+				continue
+			}
+			itemErr := &ElasticsearchItemError{Status: result.Status}
+			if result.Error != nil {
+				itemErr.Type, itemErr.Reason = result.Error.Type, result.Error.Reason
+			}
+			document := documents[i]
+			document.attempts++
+			requeued := container.retryable(result.Status) && document.attempts < container.config.MaxAttempts
+			if requeued {
+				requeue = append(requeue, document)
+			}
+			partialErr.Failed = append(partialErr.Failed, FailedElement[T]{Element: document.element, Err: itemErr, Requeued: requeued})
+		}
+	}
+	container.batch.restore(requeue)
+	if len(partialErr.Failed) == 0 {
+		return nil
+	}
+	return partialErr
+}
+
+// IsFull implement interface Container
+//
+//	@receiver container *ElasticsearchBulkContainer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 14:22:37
+func (container *ElasticsearchBulkContainer[T]) IsFull() bool {
+	return container.batch.isFull()
+}
+
+// Reset implement interface Container
+//
+//	@receiver container *ElasticsearchBulkContainer[T]
+//	@author kevineluo
+//	@update 2026-10-18 14:22:37
+func (container *ElasticsearchBulkContainer[T]) Reset() {
+	container.batch.reset()
+}
+
+// KeepFailed implement interface Keeper, requeued documents are kept in the container
+//
+//	@receiver container *ElasticsearchBulkContainer[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *ElasticsearchBulkContainer[T]) KeepFailed() {}
+
+// Len return the number of pending documents, including requeued ones
+//
+//	@receiver container *ElasticsearchBulkContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 14:22:37
+func (container *ElasticsearchBulkContainer[T]) Len() int {
+	return container.batch.len()
+}
+
+// render render documents as the NDJSON body of a bulk request: one action line followed by one source line per document
+//
+//	@receiver container *ElasticsearchBulkContainer[T]
+//	@param documents []elasticsearchDocument[T]
+//	@param now time.Time
+//	@return []byte
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 14:22:37
+func (container *ElasticsearchBulkContainer[T]) render(documents []elasticsearchDocument[T], now time.Time) ([]byte, error) {
+	var index string
+	if container.config.Index == nil {
+		values := timeTemplateValues(now)
+		values["buffer_id"] = container.config.ID
+		index = expandTemplate(container.config.IndexTemplate, values)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, document := range documents {
+		metadata := map[string]string{"_index": index}
+		if container.config.Index != nil {
+			metadata["_index"] = container.config.Index(document.element)
+		}
+		if container.config.DocumentID != nil {
+			if id := container.config.DocumentID(document.element); id != "" {
+				metadata["_id"] = id
+			}
+		}
+		if err := encoder.Encode(map[string]map[string]string{container.config.Action: metadata}); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(document.element); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// send post the bulk request, retryable is false when the request is rejected with a non-retryable status,
+// or the cluster has accepted it(2xx) but the response can't be decoded, since sending it again would duplicate documents
+//
+//	@receiver container *ElasticsearchBulkContainer[T]
+//	@param body []byte
+//	@return result *bulkResponse
+//	@return retryable bool
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-19 15:03:27
+func (container *ElasticsearchBulkContainer[T]) send(body []byte) (result *bulkResponse, retryable bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), container.config.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(container.config.URL, "/")+"/_bulk", bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	for name, values := range container.config.Header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/x-ndjson")
+
+	response, err := container.config.HTTPClient.Do(request)
+	if err != nil {
+		return nil, true, err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	if response.StatusCode/100 != 2 {
+		statusErr := &HTTPStatusError{StatusCode: response.StatusCode, Body: truncate(string(responseBody), 4096)}
+		return nil, statusErr.Retryable(), statusErr
+	}
+
+	result = &bulkResponse{}
+	if err = json.Unmarshal(responseBody, result); err != nil {
+		return nil, false, fmt.Errorf("fail to decode bulk response: %w", err)
+	}
+	return result, false, nil
+}
+
+func (container *ElasticsearchBulkContainer[T]) retryable(status int) bool {
+	for _, retryableStatus := range container.config.RetryableStatus {
+		if status == retryableStatus {
+			return true
+		}
+	}
+	return false
+}
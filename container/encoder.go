@@ -0,0 +1,236 @@
+package container
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Encoder encode a batch of elements into bytes, used by containers which write a batch as a whole object
+//
+//	@author kevineluo
+//	@update 2026-10-18 11:20:45
+type Encoder[T any] interface {
+	// Encode write the encoded batch into writer
+	Encode(writer io.Writer, batch []T) error
+	// Extension return the file extension without leading dot, e.g. "jsonl"
+	Extension() string
+	// ContentType return the MIME type of encoded data
+	ContentType() string
+}
+
+var (
+	_ Encoder[int] = JSONArrayEncoder[int]{}
+	_ Encoder[int] = JSONLEncoder[int]{}
+	_ Encoder[int] = CSVEncoder[int]{}
+	_ Encoder[int] = &ParquetEncoder[int]{}
+	_ Encoder[int] = ProtobufEncoder[int]{}
+)
+
+// JSONArrayEncoder encode the batch as one JSON array
+//
+//	@author kevineluo
+//	@update 2026-10-18 13:24:08
+type JSONArrayEncoder[T any] struct{}
+
+// Encode implement interface Encoder
+//
+//	@receiver encoder JSONArrayEncoder[T]
+//	@param writer io.Writer
+//	@param batch []T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 13:24:08
+func (encoder JSONArrayEncoder[T]) Encode(writer io.Writer, batch []T) error {
+	if batch == nil {
+		batch = []T{}
+	}
+	return json.NewEncoder(writer).Encode(batch)
+}
+
+// Extension implement interface Encoder
+func (encoder JSONArrayEncoder[T]) Extension() string {
+	return "json"
+}
+
+// ContentType implement interface Encoder
+func (encoder JSONArrayEncoder[T]) ContentType() string {
+	return "application/json"
+}
+
+// JSONLEncoder encode every element as one line of JSON
+//
+//	@author kevineluo
+//	@update 2026-10-18 11:20:45
+type JSONLEncoder[T any] struct{}
+
+// Encode implement interface Encoder
+//
+//	@receiver encoder JSONLEncoder[T]
+//	@param writer io.Writer
+//	@param batch []T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 11:20:45
+func (encoder JSONLEncoder[T]) Encode(writer io.Writer, batch []T) error {
+	jsonEncoder := json.NewEncoder(writer)
+	for _, element := range batch {
+		if err := jsonEncoder.Encode(element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Extension implement interface Encoder
+func (encoder JSONLEncoder[T]) Extension() string {
+	return "jsonl"
+}
+
+// ContentType implement interface Encoder
+func (encoder JSONLEncoder[T]) ContentType() string {
+	return "application/x-ndjson"
+}
+
+// CSVEncoder encode every element as one CSV record with the Record function, Header would be written first if it is not empty
+//
+//	@author kevineluo
+//	@update 2026-10-18 11:20:45
+type CSVEncoder[T any] struct {
+	Header []string                  // optional header line
+	Record func(T) ([]string, error) // convert an element into CSV fields
+}
+
+// Encode implement interface Encoder
+//
+//	@receiver encoder CSVEncoder[T]
+//	@param writer io.Writer
+//	@param batch []T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 11:20:45
+func (encoder CSVEncoder[T]) Encode(writer io.Writer, batch []T) error {
+	if encoder.Record == nil {
+		return fmt.Errorf("[CSVEncoder.Encode] Record function should not be nil")
+	}
+	csvWriter := csv.NewWriter(writer)
+	if len(encoder.Header) > 0 {
+		if err := csvWriter.Write(encoder.Header); err != nil {
+			return err
+		}
+	}
+	for _, element := range batch {
+		record, err := encoder.Record(element)
+		if err != nil {
+			return err
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Extension implement interface Encoder
+func (encoder CSVEncoder[T]) Extension() string {
+	return "csv"
+}
+
+// ContentType implement interface Encoder
+func (encoder CSVEncoder[T]) ContentType() string {
+	return "text/csv"
+}
+
+// ParquetEncoder encode a batch as a whole parquet file with one row group, see ParquetContainer for the struct tags of `T`
+//
+//	@author kevineluo
+//	@update 2026-10-18 11:20:45
+type ParquetEncoder[T any] struct {
+	codec   ParquetCodec
+	columns []parquetColumn
+}
+
+// NewParquetEncoder new a ParquetEncoder
+//
+//	@param codec ParquetCodec
+//	@return *ParquetEncoder[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 11:20:45
+func NewParquetEncoder[T any](codec ParquetCodec) (*ParquetEncoder[T], error) {
+	columns, err := newParquetSchema(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &ParquetEncoder[T]{codec: codec, columns: columns}, nil
+}
+
+// Encode implement interface Encoder
+//
+//	@receiver encoder *ParquetEncoder[T]
+//	@param writer io.Writer
+//	@param batch []T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 11:20:45
+func (encoder *ParquetEncoder[T]) Encode(writer io.Writer, batch []T) error {
+	parquetWriter, err := newParquetWriter[T](writer, encoder.columns, encoder.codec)
+	if err != nil {
+		return err
+	}
+	if err = parquetWriter.writeRowGroup(batch); err != nil {
+		return err
+	}
+	return parquetWriter.close()
+}
+
+// Extension implement interface Encoder
+func (encoder *ParquetEncoder[T]) Extension() string {
+	return "parquet"
+}
+
+// ContentType implement interface Encoder
+func (encoder *ParquetEncoder[T]) ContentType() string {
+	return "application/vnd.apache.parquet"
+}
+
+// ProtobufEncoder encode the batch with the Marshal function, which usually wraps the batch into a generated message and call proto.Marshal
+//
+//	@author kevineluo
+//	@update 2026-10-18 13:24:08
+type ProtobufEncoder[T any] struct {
+	Marshal func(batch []T) ([]byte, error) // marshal the batch into protobuf wire format
+}
+
+// Encode implement interface Encoder
+//
+//	@receiver encoder ProtobufEncoder[T]
+//	@param writer io.Writer
+//	@param batch []T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 13:24:08
+func (encoder ProtobufEncoder[T]) Encode(writer io.Writer, batch []T) error {
+	if encoder.Marshal == nil {
+		return fmt.Errorf("[ProtobufEncoder.Encode] Marshal function should not be nil")
+	}
+	data, err := encoder.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// Extension implement interface Encoder
+func (encoder ProtobufEncoder[T]) Extension() string {
+	return "pb"
+}
+
+// ContentType implement interface Encoder
+func (encoder ProtobufEncoder[T]) ContentType() string {
+	return "application/x-protobuf"
+}
@@ -0,0 +1,99 @@
+package container
+
+import (
+	"fmt"
+)
+
+// FailedElement an element which fails to be flushed
+//
+//	@author kevineluo
+//	@update 2026-10-18 13:10:52
+type FailedElement[T any] struct {
+	Element  T     // the failed element
+	Err      error // why the element fails
+	Requeued bool  // the element has been put back into the container and would be flushed again by next Flush
+}
+
+// PartialFlushError indicate that part of a batch fails to be flushed, while the others succeed
+//
+//	@author kevineluo
+//	@update 2026-10-18 13:10:52
+type PartialFlushError[T any] struct {
+	Total  int                // size of the flushed batch
+	Failed []FailedElement[T] // failed elements
+}
+
+// Error implement interface error
+//
+//	@receiver err *PartialFlushError[T]
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-18 13:10:52
+func (err *PartialFlushError[T]) Error() string {
+	if len(err.Failed) == 0 {
+		return fmt.Sprintf("0 of %d elements failed to flush", err.Total)
+	}
+	return fmt.Sprintf("%d of %d elements failed to flush(%d requeued), first error: %v", len(err.Failed), err.Total, err.Requeued(), err.Failed[0].Err)
+}
+
+// Unwrap return errors of failed elements, so errors.Is and errors.As can inspect them
+//
+//	@receiver err *PartialFlushError[T]
+//	@return []error
+//	@author kevineluo
+//	@update 2026-10-18 13:10:52
+func (err *PartialFlushError[T]) Unwrap() []error {
+	errs := make([]error, 0, len(err.Failed))
+	for _, failed := range err.Failed {
+		errs = append(errs, failed.Err)
+	}
+	return errs
+}
+
+// Requeued return the number of failed elements which have been put back into the container
+//
+//	@receiver err *PartialFlushError[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 13:10:52
+func (err *PartialFlushError[T]) Requeued() (requeued int) {
+	for _, failed := range err.Failed {
+		if failed.Requeued {
+			requeued++
+		}
+	}
+	return
+}
+
+// KeptError indicate that a whole batch fails to be flushed, and it's kept in the container to be flushed again by next Flush,
+// except Dropped elements which exceed the limit of the container, e.g. the oldest blocks of a full retry queue
+//
+//	@author kevineluo
+//	@update 2026-10-19 12:08:43
+type KeptError struct {
+	Dropped int   // elements dropped by the container instead of being kept
+	Err     error // why the batch fails
+}
+
+// Error implement interface error
+//
+//	@receiver err *KeptError
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-19 12:08:43
+func (err *KeptError) Error() string {
+	if err.Dropped > 0 {
+		return fmt.Sprintf("%v(kept for next Flush except %d dropped)", err.Err, err.Dropped)
+	}
+	return fmt.Sprintf("%v(kept for next Flush)", err.Err)
+}
+
+// Unwrap return the underlying error
+//
+//	@receiver err *KeptError
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 10:12:05
+func (err *KeptError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,282 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var (
+	_ Container[int] = &GRPCStreamContainer[int, int]{}
+	_ Keeper         = &GRPCStreamContainer[int, int]{}
+)
+
+// GRPCSendStream the client side of a client-streaming RPC.
+// the generated client stream, e.g. `pb.Collector_UploadClient`, satisfies it with M as the request message type.
+// RecvMsg comes from the embedded grpc.ClientStream, it's used to fetch the status of a stream terminated by server
+// into the message created by GRPCStreamConfig.Response
+type GRPCSendStream[M any] interface {
+	Send(M) error
+	CloseSend() error
+	RecvMsg(m any) error
+}
+
+// GRPCStreamConfig GRPCStreamContainer Config
+//
+//	@author kevineluo
+//	@update 2026-10-19 15:21:09
+type GRPCStreamConfig[T any, M any] struct {
+	FlushSize int // container would be full when holding FlushSize elements
+	// Open open a new client stream, e.g. `func(ctx context.Context) (GRPCSendStream[*pb.Batch], error) { return client.Upload(ctx) }`,
+	// ctx would be canceled when the stream is abandoned or the container is closed
+	Open func(ctx context.Context) (GRPCSendStream[M], error)
+	// Messages convert a batch into one or more messages, e.g. split it into chunks under the max message size
+	Messages func(batch []T) ([]M, error)
+	// Response optional, new a response message of the RPC for RecvMsg, e.g. `func() any { return new(pb.UploadResponse) }`.
+	// default is *emptypb.Empty, which can hold any protobuf response since unknown fields are skipped
+	Response func() any
+	// CloseStream optional, finish the stream when the container is closed, default is CloseSend.
+	// set it to call CloseAndRecv of the generated stream to wait for the response of server
+	CloseStream func(stream GRPCSendStream[M]) error
+	// CloseEveryFlush finish the stream with CloseStream at the end of every Flush instead of keeping it open,
+	// so a batch is flushed only after the server responds(CloseStream should wait for the response, e.g. CloseAndRecv)
+	CloseEveryFlush bool
+	// DisableResend don't send the messages of the previous Flush again when the stream is reopened after a break,
+	// so delivery of the batch in flight is at-most-once instead of at-least-once
+	DisableResend       bool
+	MaxReconnects       int           // max reconnects in a Flush when the stream breaks, default is 3
+	ReconnectBackoff    time.Duration // wait before reconnecting, doubled by every reconnect, default is 100ms
+	MaxReconnectBackoff time.Duration // max wait before reconnecting, default is 5s
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *GRPCStreamConfig[T, M]
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-19 15:21:09
+func (config *GRPCStreamConfig[T, M]) Validate() (err error) {
+	if config.FlushSize <= 0 {
+		return fmt.Errorf("[GRPCStreamConfig.Validate] invalid FlushSize: %d, FlushSize should be positive", config.FlushSize)
+	}
+	if config.Open == nil || config.Messages == nil {
+		return errors.New("[GRPCStreamConfig.Validate] Open and Messages should not be nil")
+	}
+	if config.Response == nil {
+		config.Response = func() any { return &emptypb.Empty{} }
+	}
+	if config.CloseStream == nil {
+		config.CloseStream = func(stream GRPCSendStream[M]) error { return stream.CloseSend() }
+	}
+	if config.MaxReconnects < 0 {
+		return fmt.Errorf("[GRPCStreamConfig.Validate] invalid MaxReconnects: %d, MaxReconnects should not be negative", config.MaxReconnects)
+	}
+	if config.MaxReconnects == 0 {
+		config.MaxReconnects = 3
+	}
+	if config.ReconnectBackoff <= 0 {
+		config.ReconnectBackoff = 100 * time.Millisecond
+	}
+	if config.MaxReconnectBackoff <= 0 {
+		config.MaxReconnectBackoff = 5 * time.Second
+	}
+	return
+}
+
+// GRPCStreamContainer hold an open client-streaming RPC and send every batch as one or more messages on it.
+// when the stream breaks, it would be abandoned and reopened with backoff, and the batch would be sent again
+// from its first message, so the server should tolerate duplicated messages;
+// the batch would be kept for next Flush when the stream still can't be reopened after MaxReconnects.
+//
+// Send of gRPC only queues a message on the client, so a Flush succeeds once its messages are queued, and a break of
+// the stream is noticed by the next Flush. since messages queued before the break may be lost, the messages of the previous
+// Flush are sent again ahead of the batch when the stream is reopened, unless DisableResend is set.
+// set CloseEveryFlush to wait for the response of server on every Flush, at the cost of opening a stream per Flush.
+// Put can be called while an async Flush is running, flushes run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 15:21:09
+type GRPCStreamContainer[T any, M any] struct {
+	config   GRPCStreamConfig[T, M]
+	batch    batch[T]
+	stream   GRPCSendStream[M]
+	cancel   context.CancelFunc // cancel the context of stream
+	inFlight []M                // messages of the previous Flush not confirmed by server, sent again when the stream is reopened
+}
+
+// NewGRPCStreamContainer new a GRPCStreamContainer, the stream would be opened by the first Flush
+//
+//	@param config GRPCStreamConfig[T, M]
+//	@return *GRPCStreamContainer[T, M]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:24:16
+func NewGRPCStreamContainer[T any, M any](config GRPCStreamConfig[T, M]) (*GRPCStreamContainer[T, M], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &GRPCStreamContainer[T, M]{
+		config: config,
+		batch:  newBatch[T](config.FlushSize),
+	}, nil
+}
+
+// Put implement interface Container
+//
+//	@receiver container *GRPCStreamContainer[T, M]
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:24:16
+func (container *GRPCStreamContainer[T, M]) Put(element T) error {
+	container.batch.put(element)
+	return nil
+}
+
+// Flush implement interface Container, send pending elements on the stream
+//
+//	@receiver container *GRPCStreamContainer[T, M]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 15:21:09
+func (container *GRPCStreamContainer[T, M]) Flush() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	if container.batch.len() == 0 {
+		return nil
+	}
+	elements := container.batch.take()
+
+	messages, err := container.config.Messages(elements)
+	if err != nil {
+		return fmt.Errorf("[GRPCStreamContainer.Flush] fail to convert batch into messages: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		var sent []M
+		if sent, err = container.send(messages); err == nil && container.config.CloseEveryFlush {
+			err = container.closeStream()
+		}
+		if err == nil {
+			if !container.config.DisableResend && !container.config.CloseEveryFlush {
+				container.inFlight = sent
+			}
+			return nil
+		}
+		// the stream is broken, abandon it and reopen by next attempt
+		container.abandon()
+		if attempt >= container.config.MaxReconnects {
+			container.batch.restore(elements)
+			return &KeptError{Err: fmt.Errorf("[GRPCStreamContainer.Flush] fail to send batch after %d reconnects: %w", attempt, err)}
+		}
+		backoff := container.config.ReconnectBackoff << attempt
+		if backoff > container.config.MaxReconnectBackoff || backoff <= 0 {
+			backoff = container.config.MaxReconnectBackoff
+		}
+		time.Sleep(backoff)
+	}
+}
+
+// IsFull implement interface Container
+//
+//	@receiver container *GRPCStreamContainer[T, M]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 17:24:16
+func (container *GRPCStreamContainer[T, M]) IsFull() bool {
+	return container.batch.isFull()
+}
+
+// Reset implement interface Container
+//
+//	@receiver container *GRPCStreamContainer[T, M]
+//	@author kevineluo
+//	@update 2026-10-18 17:24:16
+func (container *GRPCStreamContainer[T, M]) Reset() {
+	container.batch.reset()
+}
+
+// KeepFailed implement interface Keeper, a batch failed after MaxReconnects is kept in the container
+//
+//	@receiver container *GRPCStreamContainer[T, M]
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *GRPCStreamContainer[T, M]) KeepFailed() {}
+
+// Len return the number of pending elements
+//
+//	@receiver container *GRPCStreamContainer[T, M]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 17:24:16
+func (container *GRPCStreamContainer[T, M]) Len() int {
+	return container.batch.len()
+}
+
+// Close finish the stream with CloseStream, Buffer would call it after the final flush
+//
+//	@receiver container *GRPCStreamContainer[T, M]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 11:46:30
+func (container *GRPCStreamContainer[T, M]) Close() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	return container.closeStream()
+}
+
+// closeStream close the stream and wait for the response of server, the caller should hold batch.flushing.
+// messages in flight are confirmed when CloseStream succeeds
+func (container *GRPCStreamContainer[T, M]) closeStream() error {
+	if container.stream == nil {
+		return nil
+	}
+	err := container.config.CloseStream(container.stream)
+	if err == nil {
+		container.inFlight = nil
+	}
+	container.cancel()
+	container.stream, container.cancel = nil, nil
+	return err
+}
+
+// send open the stream if necessary and send all messages on it, a newly opened stream sends the messages in flight first.
+// return the messages sent
+func (container *GRPCStreamContainer[T, M]) send(messages []M) ([]M, error) {
+	if container.stream == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := container.config.Open(ctx)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("fail to open stream: %w", err)
+		}
+		container.stream, container.cancel = stream, cancel
+		if len(container.inFlight) > 0 {
+			messages = append(append(make([]M, 0, len(container.inFlight)+len(messages)), container.inFlight...), messages...)
+		}
+	}
+	for _, message := range messages {
+		if err := container.stream.Send(message); err != nil {
+			if errors.Is(err, io.EOF) {
+				// the stream is terminated by server, whose status is returned by RecvMsg
+				if recvErr := container.stream.RecvMsg(container.config.Response()); recvErr != nil && !errors.Is(recvErr, io.EOF) {
+					return nil, fmt.Errorf("stream is terminated by server: %w", recvErr)
+				}
+			}
+			return nil, err
+		}
+	}
+	return messages, nil
+}
+
+// abandon cancel the context of the broken stream
+func (container *GRPCStreamContainer[T, M]) abandon() {
+	if container.cancel != nil {
+		container.cancel()
+	}
+	container.stream, container.cancel = nil, nil
+}
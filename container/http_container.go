@@ -0,0 +1,358 @@
+package container
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	_ Container[int] = &HTTPContainer[int]{}
+	_ Keeper         = &HTTPContainer[int]{}
+)
+
+// HTTPResponseHandler inspect a 2xx response of a batch request, return the errors of rejected elements indexed by their position in batch
+type HTTPResponseHandler[T any] func(response *http.Response, body []byte, batch []T) (rejected map[int]error, err error)
+
+// HTTPConfig HTTPContainer Config
+//
+//	@author kevineluo
+//	@update 2026-10-19 15:46:52
+type HTTPConfig[T any] struct {
+	FlushSize int         // container would be full when holding FlushSize elements
+	URL       string      // endpoint of the batch request
+	Method    string      // method of the batch request, default is POST
+	Header    http.Header // extra headers of the batch request, e.g. Authorization
+	// Encoder encode a batch into request body, JSONArrayEncoder(default), JSONLEncoder(NDJSON) and ProtobufEncoder are usually used
+	Encoder      Encoder[T]
+	Gzip         bool          // compress request body with gzip and set Content-Encoding
+	Timeout      time.Duration // timeout of every request, default is 10s
+	MaxRetries   int           // max retries when the request fails with network error, 429 or 5xx, default is 0 -- no retry
+	RetryBackoff time.Duration // base wait before retry when there is no Retry-After header, doubled by every retry, default is 500ms
+	MaxRetryWait time.Duration // max wait before retry, Retry-After larger than it would be truncated, default is 30s
+	// HandleResponse optional, parse the 2xx response for partial failure. when it's nil, a 2xx response means the whole batch succeeds.
+	// an error returned by it fails the whole batch, which is retried like a 5xx response if the error has `Retryable() bool` returning true
+	HandleResponse HTTPResponseHandler[T]
+	// RequeueRejected put elements rejected by HandleResponse back into the container, so they would be sent again by next Flush
+	RequeueRejected bool
+	HTTPClient      *http.Client // default is http.DefaultClient
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *HTTPConfig[T]
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 13:40:19
+func (config *HTTPConfig[T]) Validate() (err error) {
+	if config.FlushSize <= 0 {
+		return fmt.Errorf("[HTTPConfig.Validate] invalid FlushSize: %d, FlushSize should be positive", config.FlushSize)
+	}
+	if config.URL == "" {
+		return errors.New("[HTTPConfig.Validate] URL should not be empty")
+	}
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
+	if config.Encoder == nil {
+		config.Encoder = JSONArrayEncoder[T]{}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxRetries < 0 {
+		return fmt.Errorf("[HTTPConfig.Validate] invalid MaxRetries: %d, MaxRetries should not be negative", config.MaxRetries)
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if config.MaxRetryWait <= 0 {
+		config.MaxRetryWait = 30 * time.Second
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return
+}
+
+// HTTPStatusError indicate the server respond with an unexpected status code
+//
+//	@author kevineluo
+//	@update 2026-10-18 13:40:19
+type HTTPStatusError struct {
+	StatusCode int    // response status code
+	Body       string // response body, truncated to 4KB
+}
+
+// Error implement interface error
+func (err *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", err.StatusCode, err.Body)
+}
+
+// Retryable return true if the request may succeed by retrying: 429 Too Many Requests or 5xx
+func (err *HTTPStatusError) Retryable() bool {
+	return retryableStatus(err.StatusCode)
+}
+
+// HTTPContainer flush every batch as a single HTTP request, retry on network error, 429 and 5xx with Retry-After honored.
+// Put can be called while an async Flush is running, flushes run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 14:02:11
+type HTTPContainer[T any] struct {
+	config HTTPConfig[T]
+	batch  batch[T]
+}
+
+// NewHTTPContainer new a HTTPContainer
+//
+//	@param config HTTPConfig[T]
+//	@return *HTTPContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 13:40:19
+func NewHTTPContainer[T any](config HTTPConfig[T]) (*HTTPContainer[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &HTTPContainer[T]{
+		config: config,
+		batch:  newBatch[T](config.FlushSize),
+	}, nil
+}
+
+// Put implement interface Container
+//
+//	@receiver container *HTTPContainer[T]
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 13:40:19
+func (container *HTTPContainer[T]) Put(element T) error {
+	container.batch.put(element)
+	return nil
+}
+
+// Flush implement interface Container, send pending elements as one request.
+// when the request still fails with retryable error after retries, elements would be kept for next Flush;
+// when HandleResponse reports rejected elements, a *PartialFlushError[T] would be returned
+//
+//	@receiver container *HTTPContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 11:02:36
+func (container *HTTPContainer[T]) Flush() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	if container.batch.len() == 0 {
+		return nil
+	}
+	elements := container.batch.take()
+
+	body, err := container.encode(elements)
+	if err != nil {
+		return fmt.Errorf("[HTTPContainer.Flush] fail to encode batch: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		var (
+			rejected  map[int]error
+			retryWait time.Duration
+			retryable bool
+		)
+		rejected, retryWait, retryable, err = container.send(body, elements)
+		if err == nil {
+			return container.handleRejected(elements, rejected)
+		}
+		if !retryable {
+			return fmt.Errorf("[HTTPContainer.Flush] fail to send batch: %w", err)
+		}
+		if attempt >= container.config.MaxRetries {
+			container.batch.restore(elements)
+			return &KeptError{Err: fmt.Errorf("[HTTPContainer.Flush] fail to send batch after %d retries: %w", attempt, err)}
+		}
+		if retryWait <= 0 {
+			retryWait = container.config.RetryBackoff << attempt
+		}
+		if retryWait > container.config.MaxRetryWait {
+			retryWait = container.config.MaxRetryWait
+		}
+		time.Sleep(retryWait)
+	}
+}
+
+// IsFull implement interface Container
+//
+//	@receiver container *HTTPContainer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 13:40:19
+func (container *HTTPContainer[T]) IsFull() bool {
+	return container.batch.isFull()
+}
+
+// Reset implement interface Container
+//
+//	@receiver container *HTTPContainer[T]
+//	@author kevineluo
+//	@update 2026-10-18 13:40:19
+func (container *HTTPContainer[T]) Reset() {
+	container.batch.reset()
+}
+
+// KeepFailed implement interface Keeper, a batch failed after retries and elements rejected with RequeueRejected are kept in the container
+//
+//	@receiver container *HTTPContainer[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *HTTPContainer[T]) KeepFailed() {}
+
+// Len return the number of pending elements
+//
+//	@receiver container *HTTPContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 13:40:19
+func (container *HTTPContainer[T]) Len() int {
+	return container.batch.len()
+}
+
+func (container *HTTPContainer[T]) encode(elements []T) ([]byte, error) {
+	var buf bytes.Buffer
+	if !container.config.Gzip {
+		err := container.config.Encoder.Encode(&buf, elements)
+		return buf.Bytes(), err
+	}
+	writer := gzip.NewWriter(&buf)
+	if err := container.config.Encoder.Encode(writer, elements); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// send send the request once
+//
+//	@receiver container *HTTPContainer[T]
+//	@param body []byte
+//	@param elements []T
+//	@return rejected map[int]error
+//	@return retryWait time.Duration wait duration required by Retry-After, 0 if absent
+//	@return retryable bool
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-19 15:46:52
+func (container *HTTPContainer[T]) send(body []byte, elements []T) (rejected map[int]error, retryWait time.Duration, retryable bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), container.config.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, container.config.Method, container.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	for name, values := range container.config.Header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", container.config.Encoder.ContentType())
+	if container.config.Gzip {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+
+	response, err := container.config.HTTPClient.Do(request)
+	if err != nil {
+		// network error or timeout
+		return nil, 0, true, err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, true, err
+	}
+
+	if response.StatusCode/100 != 2 {
+		statusErr := &HTTPStatusError{StatusCode: response.StatusCode, Body: truncate(string(responseBody), 4096)}
+		retryWait, _ = parseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+		return nil, retryWait, statusErr.Retryable(), statusErr
+	}
+	if container.config.HandleResponse != nil {
+		if rejected, err = container.config.HandleResponse(response, responseBody, elements); err != nil {
+			var retryableErr interface{ Retryable() bool }
+			retryable = errors.As(err, &retryableErr) && retryableErr.Retryable()
+			return nil, 0, retryable, fmt.Errorf("fail to handle response: %w", err)
+		}
+	}
+	return
+}
+
+func (container *HTTPContainer[T]) handleRejected(elements []T, rejected map[int]error) error {
+	if len(rejected) == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, len(rejected))
+	for index := range rejected {
+		if index >= 0 && index < len(elements) {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+
+	partialErr := &PartialFlushError[T]{Total: len(elements)}
+	requeue := make([]T, 0, len(indexes))
+	for _, index := range indexes {
+		partialErr.Failed = append(partialErr.Failed, FailedElement[T]{
+			Element:  elements[index],
+			Err:      rejected[index],
+			Requeued: container.config.RequeueRejected,
+		})
+		requeue = append(requeue, elements[index])
+	}
+	if container.config.RequeueRejected {
+		container.batch.restore(requeue)
+	}
+	return partialErr
+}
+
+// retryableStatus return true for 429 Too Many Requests and 5xx
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode/100 == 5
+}
+
+// parseRetryAfter parse the Retry-After header, which is either delay seconds or a HTTP date
+//
+//	@param value string
+//	@param now time.Time
+//	@return time.Duration
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 13:40:19
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
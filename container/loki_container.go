@@ -0,0 +1,438 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+)
+
+var (
+	_ Container[int] = &LokiContainer[int]{}
+	_ Keeper         = &LokiContainer[int]{}
+)
+
+// LokiEntry a Loki log entry converted from a buffered element
+//
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+type LokiEntry struct {
+	Timestamp          time.Time         // timestamp of the entry, default is the flush time
+	Line               string            // log line
+	StructuredMetadata map[string]string // optional, structured metadata attached to the entry(Loki 2.9+)
+}
+
+// LokiConfig LokiContainer Config
+//
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+type LokiConfig[T any] struct {
+	FlushSize int    // container would be full when holding FlushSize entries
+	URL       string // base url of Loki, e.g. "http://localhost:3100", the request would be sent to URL/loki/api/v1/push
+	TenantID  string // optional, sent as X-Scope-OrgID header in multi-tenant mode
+	// Labels decide the stream of an element, entries with the same labels are pushed as one stream
+	Labels     func(T) map[string]string
+	Entry      func(T) LokiEntry // convert an element into log entry
+	Header     http.Header       // extra headers of the push request, e.g. Authorization
+	Timeout    time.Duration     // timeout of the push request, default is 10s
+	HTTPClient *http.Client      // default is http.DefaultClient
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *LokiConfig[T]
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+func (config *LokiConfig[T]) Validate() (err error) {
+	if config.FlushSize <= 0 {
+		return fmt.Errorf("[LokiConfig.Validate] invalid FlushSize: %d, FlushSize should be positive", config.FlushSize)
+	}
+	if config.URL == "" {
+		return errors.New("[LokiConfig.Validate] URL should not be empty")
+	}
+	if config.Labels == nil || config.Entry == nil {
+		return errors.New("[LokiConfig.Validate] Labels and Entry should not be nil")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return
+}
+
+// LokiStreamError entries of a stream rejected by Loki, e.g. out of order or too old
+//
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+type LokiStreamError struct {
+	Labels  string   // labels of the stream, e.g. {app="api", env="prod"}
+	Reasons []string // reasons reported by Loki
+}
+
+// Error implement interface error
+func (err *LokiStreamError) Error() string {
+	return fmt.Sprintf("entries of stream %s rejected: %s", err.Labels, strings.Join(err.Reasons, "; "))
+}
+
+// lokiStream entries of a stream sorted by timestamp, index is the position of the element in batch
+type lokiStream struct {
+	labels  string
+	entries []LokiEntry
+	indexes []int
+}
+
+var (
+	// lokiEntryPattern match "entry with timestamp 2024-01-01 00:00:00 +0000 UTC ignored, reason: 'entry out of order',"
+	lokiEntryPattern = regexp.MustCompile(`entry with timestamp (.+?) ignored, reason: '(.*)'`)
+	// lokiStreamPattern match "... for stream: {app="api"}" and "... for stream '{app="api"}' ..."
+	lokiStreamPattern = regexp.MustCompile(`for stream:? '?(\{.*?\})'?`)
+)
+
+// LokiContainer group buffered elements into streams by labels, sort entries of every stream by timestamp,
+// and push every batch to Loki with the snappy compressed protobuf push API.
+// when Loki rejects part of entries(400 Bad Request), they would be reported per stream by *PartialFlushError[T]
+// whose Err is *LokiStreamError, they would not be retried since retrying can't fix them;
+// the whole batch would be kept for next Flush when the push fails with network error, 429 or 5xx.
+// Put can be called while an async Flush is running, flushes run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 14:02:11
+type LokiContainer[T any] struct {
+	config LokiConfig[T]
+	batch  batch[T]
+}
+
+// NewLokiContainer new a LokiContainer
+//
+//	@param config LokiConfig[T]
+//	@return *LokiContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+func NewLokiContainer[T any](config LokiConfig[T]) (*LokiContainer[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &LokiContainer[T]{
+		config: config,
+		batch:  newBatch[T](config.FlushSize),
+	}, nil
+}
+
+// Put implement interface Container
+//
+//	@receiver container *LokiContainer[T]
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+func (container *LokiContainer[T]) Put(element T) error {
+	container.batch.put(element)
+	return nil
+}
+
+// Flush implement interface Container, push pending elements as one request
+//
+//	@receiver container *LokiContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 11:24:37
+func (container *LokiContainer[T]) Flush() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	if container.batch.len() == 0 {
+		return nil
+	}
+	elements := container.batch.take()
+
+	streams := container.group(elements, time.Now())
+	body := snappy.Encode(nil, marshalLokiPushRequest(streams))
+
+	err := container.send(body)
+	if err == nil {
+		return nil
+	}
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.Retryable() {
+		// the whole request may succeed by retrying
+		container.batch.restore(elements)
+		return &KeptError{Err: fmt.Errorf("[LokiContainer.Flush] fail to push entries: %w", err)}
+	}
+	if statusErr.StatusCode == http.StatusBadRequest {
+		if partialErr := rejectedEntries(elements, streams, statusErr.Body); partialErr != nil {
+			return partialErr
+		}
+	}
+	return fmt.Errorf("[LokiContainer.Flush] fail to push entries: %w", err)
+}
+
+// IsFull implement interface Container
+//
+//	@receiver container *LokiContainer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+func (container *LokiContainer[T]) IsFull() bool {
+	return container.batch.isFull()
+}
+
+// Reset implement interface Container
+//
+//	@receiver container *LokiContainer[T]
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+func (container *LokiContainer[T]) Reset() {
+	container.batch.reset()
+}
+
+// KeepFailed implement interface Keeper, a batch failed with network error, 429 or 5xx is kept in the container
+//
+//	@receiver container *LokiContainer[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *LokiContainer[T]) KeepFailed() {}
+
+// Len return the number of pending entries
+//
+//	@receiver container *LokiContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+func (container *LokiContainer[T]) Len() int {
+	return container.batch.len()
+}
+
+// group group elements into streams sorted by labels, entries of every stream are sorted by timestamp as Loki requires
+//
+//	@receiver container *LokiContainer[T]
+//	@param elements []T
+//	@param now time.Time
+//	@return []*lokiStream
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+func (container *LokiContainer[T]) group(elements []T, now time.Time) []*lokiStream {
+	streamMap := make(map[string]*lokiStream)
+	for i, element := range elements {
+		labels := formatLokiLabels(container.config.Labels(element))
+		stream, ok := streamMap[labels]
+		if !ok {
+			stream = &lokiStream{labels: labels}
+			streamMap[labels] = stream
+		}
+		entry := container.config.Entry(element)
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = now
+		}
+		stream.entries = append(stream.entries, entry)
+		stream.indexes = append(stream.indexes, i)
+	}
+
+	streams := make([]*lokiStream, 0, len(streamMap))
+	for _, stream := range streamMap {
+		sort.Stable(stream)
+		streams = append(streams, stream)
+	}
+	sort.Slice(streams, func(i, j int) bool { return streams[i].labels < streams[j].labels })
+	return streams
+}
+
+func (container *LokiContainer[T]) send(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), container.config.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(container.config.URL, "/")+"/loki/api/v1/push", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range container.config.Header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/x-protobuf")
+	request.Header.Set("Content-Encoding", "snappy")
+	if container.config.TenantID != "" {
+		request.Header.Set("X-Scope-OrgID", container.config.TenantID)
+	}
+
+	response, err := container.config.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode/100 != 2 {
+		// keep the whole body, rejected entries of every stream are listed in it
+		return &HTTPStatusError{StatusCode: response.StatusCode, Body: string(responseBody)}
+	}
+	return nil
+}
+
+// Len implement sort.Interface
+func (stream *lokiStream) Len() int {
+	return len(stream.entries)
+}
+
+// Less implement sort.Interface
+func (stream *lokiStream) Less(i, j int) bool {
+	return stream.entries[i].Timestamp.Before(stream.entries[j].Timestamp)
+}
+
+// Swap implement sort.Interface
+func (stream *lokiStream) Swap(i, j int) {
+	stream.entries[i], stream.entries[j] = stream.entries[j], stream.entries[i]
+	stream.indexes[i], stream.indexes[j] = stream.indexes[j], stream.indexes[i]
+}
+
+// formatLokiLabels format labels as Loki does: {a="1", b="2"} with sorted names
+func formatLokiLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(name)
+		builder.WriteByte('=')
+		builder.WriteString(strconv.Quote(labels[name]))
+	}
+	builder.WriteByte('}')
+	return builder.String()
+}
+
+// marshalLokiPushRequest encode streams as logproto.PushRequest
+func marshalLokiPushRequest(streams []*lokiStream) (request []byte) {
+	for _, stream := range streams {
+		var encoded []byte
+		encoded = appendProtoString(encoded, 1, stream.labels)
+		for _, entry := range stream.entries {
+			var timestamp, encodedEntry []byte
+			timestamp = appendProtoVarint(timestamp, 1, uint64(entry.Timestamp.Unix()))
+			timestamp = appendProtoVarint(timestamp, 2, uint64(entry.Timestamp.Nanosecond()))
+			encodedEntry = appendProtoMessage(encodedEntry, 1, timestamp)
+			encodedEntry = appendProtoString(encodedEntry, 2, entry.Line)
+			names := make([]string, 0, len(entry.StructuredMetadata))
+			for name := range entry.StructuredMetadata {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				var pair []byte
+				pair = appendProtoString(pair, 1, name)
+				pair = appendProtoString(pair, 2, entry.StructuredMetadata[name])
+				encodedEntry = appendProtoMessage(encodedEntry, 3, pair)
+			}
+			// entries = 2
+			encoded = appendProtoMessage(encoded, 2, encodedEntry)
+		}
+		// streams = 1
+		request = appendProtoMessage(request, 1, encoded)
+	}
+	return request
+}
+
+// rejectedEntries parse the body of a 400 response, which lists rejected entries like:
+//
+//	entry with timestamp 2024-01-01 00:00:00 +0000 UTC ignored, reason: 'entry out of order',
+//	user 'fake', total ignored: 1 out of 2 for stream: {app="api"}
+//
+// entries are matched by timestamp, all entries of the stream are reported when the timestamps are absent.
+// return nil if no stream of the batch can be found in the body
+//
+//	@param elements []T
+//	@param streams []*lokiStream
+//	@param body string
+//	@return *PartialFlushError[T]
+//	@author kevineluo
+//	@update 2026-10-18 17:05:42
+func rejectedEntries[T any](elements []T, streams []*lokiStream, body string) *PartialFlushError[T] {
+	streamMap := make(map[string]*lokiStream, len(streams))
+	for _, stream := range streams {
+		streamMap[stream.labels] = stream
+	}
+
+	streamErrors := make(map[string]*LokiStreamError)
+	rejectedTimestamps := make(map[string]map[int64]bool)
+	var (
+		pendingReasons    []string
+		pendingTimestamps []int64
+		order             []string
+	)
+	for _, line := range strings.Split(body, "\n") {
+		if match := lokiEntryPattern.FindStringSubmatch(line); match != nil {
+			if timestamp, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", match[1]); err == nil {
+				pendingTimestamps = append(pendingTimestamps, timestamp.UnixNano())
+			}
+			pendingReasons = append(pendingReasons, match[2])
+		}
+		match := lokiStreamPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		labels := match[1]
+		if _, ok := streamMap[labels]; ok {
+			streamErr, ok := streamErrors[labels]
+			if !ok {
+				streamErr = &LokiStreamError{Labels: labels}
+				streamErrors[labels] = streamErr
+				rejectedTimestamps[labels] = make(map[int64]bool)
+				order = append(order, labels)
+			}
+			if len(pendingReasons) == 0 {
+				// validation error of the whole stream, e.g. "entry for stream '{app="api"}' has timestamp too old"
+				pendingReasons = append(pendingReasons, strings.TrimSpace(line))
+			}
+			for _, reason := range pendingReasons {
+				if !contains(streamErr.Reasons, reason) {
+					streamErr.Reasons = append(streamErr.Reasons, reason)
+				}
+			}
+			for _, timestamp := range pendingTimestamps {
+				rejectedTimestamps[labels][timestamp] = true
+			}
+		}
+		pendingReasons, pendingTimestamps = nil, nil
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	partialErr := &PartialFlushError[T]{Total: len(elements)}
+	for _, labels := range order {
+		stream, timestamps := streamMap[labels], rejectedTimestamps[labels]
+		for i, entry := range stream.entries {
+			if len(timestamps) == 0 || timestamps[entry.Timestamp.UnixNano()] {
+				partialErr.Failed = append(partialErr.Failed, FailedElement[T]{Element: elements[stream.indexes[i]], Err: streamErrors[labels]})
+			}
+		}
+	}
+	return partialErr
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ObjectStore the storage backend of ObjectStoreContainer
+//
+//	@author kevineluo
+//	@update 2026-10-18 11:41:03
+type ObjectStore interface {
+	// Put upload body as a single object named key
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+var (
+	_ ObjectStore = &LocalObjectStore{}
+	_ ObjectStore = &S3ObjectStore{}
+)
+
+// LocalObjectStore store objects as files under the Root directory, the key is used as the relative path
+//
+//	@author kevineluo
+//	@update 2026-10-18 11:41:03
+type LocalObjectStore struct {
+	Root string // root directory of objects
+}
+
+// Put implement interface ObjectStore, the file would be written into a temporary file first and then renamed,
+// so readers would never see a partial object
+//
+//	@receiver store *LocalObjectStore
+//	@param ctx context.Context
+//	@param key string
+//	@param body []byte
+//	@param contentType string
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 11:41:03
+func (store *LocalObjectStore) Put(ctx context.Context, key string, body []byte, contentType string) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	path := filepath.Join(store.Root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(store.Root)+string(filepath.Separator)) {
+		return fmt.Errorf("[LocalObjectStore.Put] invalid object key: %s", key)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(file.Name())
+		}
+	}()
+	if _, err = file.Write(body); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// S3ObjectStore upload objects to a S3 compatible service(AWS S3, MinIO, Ceph RGW...) with PUT Object API, signed by AWS Signature Version 4
+//
+//	@author kevineluo
+//	@update 2026-10-18 11:41:03
+type S3ObjectStore struct {
+	Endpoint        string       // service endpoint with scheme, e.g. "https://s3.us-east-1.amazonaws.com"
+	Bucket          string       // target bucket
+	Region          string       // signing region, default is "us-east-1"
+	AccessKeyID     string       // access key id
+	SecretAccessKey string       // secret access key
+	SessionToken    string       // optional session token of temporary credentials
+	PathStyle       bool         // use path-style url(endpoint/bucket/key) instead of virtual-hosted-style url(bucket.endpoint/key)
+	HTTPClient      *http.Client // default is http.DefaultClient
+}
+
+// Put implement interface ObjectStore
+//
+//	@receiver store *S3ObjectStore
+//	@param ctx context.Context
+//	@param key string
+//	@param body []byte
+//	@param contentType string
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 11:41:03
+func (store *S3ObjectStore) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	endpoint, err := url.Parse(store.Endpoint)
+	if err != nil {
+		return fmt.Errorf("[S3ObjectStore.Put] invalid endpoint %s: %w", store.Endpoint, err)
+	}
+	objectURL := *endpoint
+	if store.PathStyle {
+		objectURL.Path = "/" + store.Bucket + "/" + key
+	} else {
+		objectURL.Host = store.Bucket + "." + endpoint.Host
+		objectURL.Path = "/" + key
+	}
+	objectURL.RawPath = s3URIEncode(objectURL.Path)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	store.Sign(request, body, time.Now())
+
+	client := store.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("[S3ObjectStore.Put] fail to put object %s: %w", key, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("[S3ObjectStore.Put] fail to put object %s, status: %s, response: %s", key, response.Status, message)
+	}
+	return nil
+}
+
+// Sign add AWS Signature Version 4 headers into request, every header of request would be signed
+//
+//	@receiver store *S3ObjectStore
+//	@param request *http.Request
+//	@param body []byte
+//	@param now time.Time
+//	@author kevineluo
+//	@update 2026-10-19 14:31:52
+func (store *S3ObjectStore) Sign(request *http.Request, body []byte, now time.Time) {
+	region := store.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256.Sum256(body)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	if store.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", store.SessionToken)
+	}
+
+	// canonical headers: host and every header to be signed, in lower case and sorted
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		s3URIEncode(request.URL.Path),
+		request.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+store.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		store.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3URIEncode encode uri path as AWS Signature Version 4 required: every byte except unreserved characters and '/' is percent-encoded
+//
+//	@param uri string
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-18 11:41:03
+func s3URIEncode(uri string) string {
+	var builder strings.Builder
+	for _, b := range []byte(uri) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~', b == '/':
+			builder.WriteByte(b)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
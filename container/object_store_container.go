@@ -0,0 +1,276 @@
+package container
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	_ Container[int] = &ObjectStoreContainer[int]{}
+	_ Keeper         = &ObjectStoreContainer[int]{}
+)
+
+// ObjectCompression compression applied on the encoded batch before uploading
+//
+//	@author kevineluo
+//	@update 2026-10-18 12:03:26
+type ObjectCompression int
+
+const (
+	ObjectUncompressed ObjectCompression = iota
+	ObjectGzip
+	ObjectZstd
+)
+
+// ObjectStoreConfig ObjectStoreContainer Config
+//
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+type ObjectStoreConfig[T any] struct {
+	ID          string            // used as {buffer_id} in KeyTemplate, required when KeyTemplate contains {buffer_id}
+	FlushSize   int               // container would be full when holding FlushSize elements
+	Encoder     Encoder[T]        // encode a batch into one object, default is JSONLEncoder
+	Compression ObjectCompression // compression of the object, default is ObjectUncompressed
+	// KeyTemplate template of object key, support placeholders: {date}, {hour}, {minute}, {timestamp}(flush time in UTC),
+	// {buffer_id}, {instance}(random id generated when the container is created), {seq}(sequence number of the object in this container)
+	// and {ext}(extension of encoder and compression, e.g. "jsonl.gz"). {seq} starts from 0 again after a restart,
+	// so keep {instance} in the template unless the keys are unique in other ways, or an existing object would be overwritten.
+	// default is "{date}/{hour}/{buffer_id}-{instance}-{seq}.{ext}"
+	KeyTemplate string
+	Store       ObjectStore   // storage backend
+	Timeout     time.Duration // timeout of uploading an object, default is 30s
+	// Size optional, estimate the encoded size of an element in bytes, e.g. `func(event Event) int { return len(event.Message) }`,
+	// Bytes of the container return the total size of pending elements, or -1 when Size is nil
+	Size func(element T) int
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *ObjectStoreConfig[T]
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-19 14:31:52
+func (config *ObjectStoreConfig[T]) Validate() (err error) {
+	if config.FlushSize <= 0 {
+		return fmt.Errorf("[ObjectStoreConfig.Validate] invalid FlushSize: %d, FlushSize should be positive", config.FlushSize)
+	}
+	if config.Store == nil {
+		return errors.New("[ObjectStoreConfig.Validate] Store should not be nil")
+	}
+	if config.Encoder == nil {
+		config.Encoder = JSONLEncoder[T]{}
+	}
+	if config.Compression < ObjectUncompressed || config.Compression > ObjectZstd {
+		return fmt.Errorf("[ObjectStoreConfig.Validate] unsupported compression: %d", config.Compression)
+	}
+	if config.KeyTemplate == "" {
+		config.KeyTemplate = "{date}/{hour}/{buffer_id}-{instance}-{seq}.{ext}"
+	}
+	if config.ID == "" && strings.Contains(config.KeyTemplate, "{buffer_id}") {
+		return errors.New("[ObjectStoreConfig.Validate] ID should be set when KeyTemplate contains {buffer_id}")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return
+}
+
+// ObjectStoreContainer encode every flushed batch, optionally compress it, and upload it as a single object through ObjectStore.
+// Put can be called while an async Flush is running, flushes run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 14:02:11
+type ObjectStoreContainer[T any] struct {
+	config   ObjectStoreConfig[T]
+	batch    batch[T]
+	instance string // {instance} in KeyTemplate
+	seq      int    // sequence number of next object
+}
+
+// NewObjectStoreContainer new an ObjectStoreContainer
+//
+//	@param config ObjectStoreConfig[T]
+//	@return *ObjectStoreContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+func NewObjectStoreContainer[T any](config ObjectStoreConfig[T]) (*ObjectStoreContainer[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &ObjectStoreContainer[T]{
+		config:   config,
+		batch:    newSizedBatch[T](config.FlushSize, config.Size),
+		instance: strings.ReplaceAll(uuid.NewString(), "-", ""),
+	}, nil
+}
+
+// Put implement interface Container
+//
+//	@receiver container *ObjectStoreContainer[T]
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 12:03:26
+func (container *ObjectStoreContainer[T]) Put(element T) error {
+	container.batch.put(element)
+	return nil
+}
+
+// Flush implement interface Container, upload pending elements as one object.
+// elements would be kept in the container when failing to upload, so next Flush would upload them again
+//
+//	@receiver container *ObjectStoreContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 11:15:09
+func (container *ObjectStoreContainer[T]) Flush() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	if container.batch.len() == 0 {
+		return nil
+	}
+	elements := container.batch.take()
+
+	body, err := container.encode(elements)
+	if err != nil {
+		// encoding error would not be recovered by retrying, so the batch is dropped
+		return fmt.Errorf("[ObjectStoreContainer.Flush] fail to encode batch: %w", err)
+	}
+
+	key := container.Key(time.Now())
+	ctx, cancel := context.WithTimeout(context.Background(), container.config.Timeout)
+	defer cancel()
+	if err = container.config.Store.Put(ctx, key, body, container.contentType()); err != nil {
+		container.batch.restore(elements)
+		return &KeptError{Err: fmt.Errorf("[ObjectStoreContainer.Flush] fail to upload object %s: %w", key, err)}
+	}
+	container.seq++
+	return nil
+}
+
+// IsFull implement interface Container
+//
+//	@receiver container *ObjectStoreContainer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 12:03:26
+func (container *ObjectStoreContainer[T]) IsFull() bool {
+	return container.batch.isFull()
+}
+
+// Reset implement interface Container
+//
+//	@receiver container *ObjectStoreContainer[T]
+//	@author kevineluo
+//	@update 2026-10-18 12:03:26
+func (container *ObjectStoreContainer[T]) Reset() {
+	container.batch.reset()
+}
+
+// KeepFailed implement interface Keeper, a batch failed to upload is kept in the container
+//
+//	@receiver container *ObjectStoreContainer[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *ObjectStoreContainer[T]) KeepFailed() {}
+
+// Len return the number of pending elements
+//
+//	@receiver container *ObjectStoreContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 12:03:26
+func (container *ObjectStoreContainer[T]) Len() int {
+	return container.batch.len()
+}
+
+// Bytes return the total size of pending elements estimated by Size, -1 if Size is not set
+//
+//	@receiver container *ObjectStoreContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+func (container *ObjectStoreContainer[T]) Bytes() int {
+	return container.batch.sizeBytes()
+}
+
+// Key render the key of next object with KeyTemplate
+//
+//	@receiver container *ObjectStoreContainer[T]
+//	@param now time.Time
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-19 14:31:52
+func (container *ObjectStoreContainer[T]) Key(now time.Time) string {
+	values := timeTemplateValues(now)
+	values["buffer_id"] = container.config.ID
+	values["instance"] = container.instance
+	values["seq"] = strconv.Itoa(container.seq)
+	values["ext"] = container.extension()
+	return expandTemplate(container.config.KeyTemplate, values)
+}
+
+func (container *ObjectStoreContainer[T]) encode(elements []T) ([]byte, error) {
+	var (
+		buf    bytes.Buffer
+		writer io.WriteCloser
+		err    error
+	)
+	switch container.config.Compression {
+	case ObjectGzip:
+		writer = gzip.NewWriter(&buf)
+	case ObjectZstd:
+		if writer, err = zstd.NewWriter(&buf); err != nil {
+			return nil, err
+		}
+	default:
+		writer = nopWriteCloser{&buf}
+	}
+	if err = container.config.Encoder.Encode(writer, elements); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (container *ObjectStoreContainer[T]) extension() string {
+	switch container.config.Compression {
+	case ObjectGzip:
+		return container.config.Encoder.Extension() + ".gz"
+	case ObjectZstd:
+		return container.config.Encoder.Extension() + ".zst"
+	default:
+		return container.config.Encoder.Extension()
+	}
+}
+
+func (container *ObjectStoreContainer[T]) contentType() string {
+	switch container.config.Compression {
+	case ObjectGzip:
+		return "application/gzip"
+	case ObjectZstd:
+		return "application/zstd"
+	default:
+		return container.config.Encoder.ContentType()
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,369 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var _ Container[int] = &OTLPContainer[int]{}
+
+// OTLPLogRecord an OTLP log record converted from a buffered element
+//
+//	@author kevineluo
+//	@update 2026-10-18 16:45:30
+type OTLPLogRecord struct {
+	Timestamp         time.Time      // when the event occurred, zero means unknown
+	ObservedTimestamp time.Time      // when the event was observed, default is the flush time
+	SeverityNumber    int32          // 1-24, e.g. 9 for INFO, 17 for ERROR
+	SeverityText      string         // e.g. "INFO"
+	Body              any            // string, bool, integer, float, []byte, []any or map[string]any
+	Attributes        map[string]any // attributes of the record
+	TraceID           [16]byte       // optional trace id
+	SpanID            [8]byte        // optional span id
+}
+
+// OTLPSpan an OTLP span converted from a buffered element
+//
+//	@author kevineluo
+//	@update 2026-10-18 16:45:30
+type OTLPSpan struct {
+	TraceID       [16]byte
+	SpanID        [8]byte
+	ParentSpanID  [8]byte // zero for root span
+	Name          string
+	Kind          int32 // 1 internal, 2 server, 3 client, 4 producer, 5 consumer
+	Start         time.Time
+	End           time.Time
+	Attributes    map[string]any
+	StatusCode    int32 // 0 unset, 1 ok, 2 error
+	StatusMessage string
+}
+
+// OTLPConfig OTLPContainer Config, exactly one of LogRecord and Span should be set, which decides the signal to export
+//
+//	@author kevineluo
+//	@update 2026-10-18 16:45:30
+type OTLPConfig[T any] struct {
+	FlushSize int // container would be full when holding FlushSize elements
+	// Endpoint base url of OTLP/HTTP receiver, e.g. "http://localhost:4318", records would be exported to Endpoint/v1/logs or Endpoint/v1/traces
+	Endpoint     string
+	Header       http.Header    // extra headers, e.g. authentication of the collector
+	Resource     map[string]any // resource attributes, e.g. {"service.name": "my-service"}
+	ScopeName    string         // instrumentation scope name, default is "github.com/Kevinello/go-buffer"
+	ScopeVersion string         // instrumentation scope version
+
+	LogRecord func(T) OTLPLogRecord // convert an element into log record
+	Span      func(T) OTLPSpan      // convert an element into span
+
+	DisableGzip  bool          // request body is gzipped by default
+	Timeout      time.Duration // timeout of every export request, default is 10s
+	MaxRetries   int           // max retries of 429/5xx and network error, default is 0 -- no retry
+	RetryBackoff time.Duration // see HTTPConfig.RetryBackoff
+	HTTPClient   *http.Client  // default is http.DefaultClient
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *OTLPConfig[T]
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 16:45:30
+func (config *OTLPConfig[T]) Validate() (err error) {
+	if config.Endpoint == "" {
+		return errors.New("[OTLPConfig.Validate] Endpoint should not be empty")
+	}
+	if (config.LogRecord == nil) == (config.Span == nil) {
+		return errors.New("[OTLPConfig.Validate] exactly one of LogRecord and Span should be set")
+	}
+	if config.ScopeName == "" {
+		config.ScopeName = "github.com/Kevinello/go-buffer"
+	}
+	return
+}
+
+// OTLPPartialSuccessError indicate the receiver accepts the request but rejects part of records
+//
+//	@author kevineluo
+//	@update 2026-10-18 16:45:30
+type OTLPPartialSuccessError struct {
+	Rejected int64  // number of rejected log records or spans
+	Message  string // error message from the receiver
+}
+
+// Error implement interface error
+func (err *OTLPPartialSuccessError) Error() string {
+	return fmt.Sprintf("otlp receiver rejected %d records: %s", err.Rejected, err.Message)
+}
+
+// OTLPContainer convert buffered elements into OTLP log records or spans, and export every batch with OTLP/HTTP protobuf.
+// it's built on HTTPContainer, so retry and Retry-After are handled in the same way;
+// partial success response would be reported by *OTLPPartialSuccessError wrapped in *KeptError, whose Dropped is the number of
+// rejected records, rejected records would not be retried. Put can be called while an async Flush is running, flushes run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 14:52:06
+type OTLPContainer[T any] struct {
+	*HTTPContainer[T]
+	config OTLPConfig[T]
+}
+
+// NewOTLPContainer new an OTLPContainer
+//
+//	@param config OTLPConfig[T]
+//	@return *OTLPContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 13:38:12
+func NewOTLPContainer[T any](config OTLPConfig[T]) (*OTLPContainer[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	container := &OTLPContainer[T]{config: config}
+	path := "/v1/logs"
+	if config.Span != nil {
+		path = "/v1/traces"
+	}
+	httpContainer, err := NewHTTPContainer(HTTPConfig[T]{
+		FlushSize:    config.FlushSize,
+		URL:          strings.TrimSuffix(config.Endpoint, "/") + path,
+		Header:       config.Header,
+		Encoder:      ProtobufEncoder[T]{Marshal: container.marshal},
+		Gzip:         !config.DisableGzip,
+		Timeout:      config.Timeout,
+		MaxRetries:   config.MaxRetries,
+		RetryBackoff: config.RetryBackoff,
+		HandleResponse: func(response *http.Response, body []byte, batch []T) (map[int]error, error) {
+			// partial success of OTLP only counts rejected records without telling which ones
+			return nil, container.handleResponse(response, body, len(batch))
+		},
+		HTTPClient: config.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	container.HTTPContainer = httpContainer
+	return container, nil
+}
+
+// marshal encode a batch into ExportLogsServiceRequest or ExportTraceServiceRequest
+//
+//	@receiver container *OTLPContainer[T]
+//	@param batch []T
+//	@return []byte
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 14:52:06
+func (container *OTLPContainer[T]) marshal(batch []T) ([]byte, error) {
+	now := time.Now()
+	var scope, scopeRecords, resourceRecords []byte
+	scope = appendProtoString(scope, 1, container.config.ScopeName)
+	scope = appendProtoString(scope, 2, container.config.ScopeVersion)
+	scopeRecords = appendProtoMessage(scopeRecords, 1, scope)
+	for _, element := range batch {
+		// log_records = 2 / spans = 2
+		if container.config.LogRecord != nil {
+			scopeRecords = appendProtoMessage(scopeRecords, 2, marshalOTLPLogRecord(container.config.LogRecord(element), now))
+		} else {
+			scopeRecords = appendProtoMessage(scopeRecords, 2, marshalOTLPSpan(container.config.Span(element)))
+		}
+	}
+	resourceRecords = appendProtoMessage(resourceRecords, 1, appendOTLPAttributes(nil, 1, container.config.Resource))
+	// scope_logs = 2 / scope_spans = 2
+	resourceRecords = appendProtoMessage(resourceRecords, 2, scopeRecords)
+	// resource_logs = 1 / resource_spans = 1
+	return appendProtoMessage(nil, 1, resourceRecords), nil
+}
+
+// handleResponse parse ExportLogsServiceResponse / ExportTraceServiceResponse for partial success,
+// rejected records are reported by *KeptError, whose Dropped is the number of rejected records
+//
+//	@receiver container *OTLPContainer[T]
+//	@param response *http.Response
+//	@param body []byte
+//	@param batchSize int
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 14:52:06
+func (container *OTLPContainer[T]) handleResponse(response *http.Response, body []byte, batchSize int) error {
+	if !strings.Contains(response.Header.Get("Content-Type"), "protobuf") || len(body) == 0 {
+		return nil
+	}
+	for len(body) > 0 {
+		num, typ, n := protowire.ConsumeTag(body)
+		if n < 0 {
+			return fmt.Errorf("invalid export response: %w", protowire.ParseError(n))
+		}
+		body = body[n:]
+		if num != 1 || typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, body); n < 0 {
+				return fmt.Errorf("invalid export response: %w", protowire.ParseError(n))
+			}
+			body = body[n:]
+			continue
+		}
+		partialSuccess, n := protowire.ConsumeBytes(body)
+		if n < 0 {
+			return fmt.Errorf("invalid export response: %w", protowire.ParseError(n))
+		}
+		body = body[n:]
+		partialErr, err := parseOTLPPartialSuccess(partialSuccess)
+		if err != nil {
+			return fmt.Errorf("invalid partial success: %w", err)
+		}
+		if partialErr.Rejected > 0 {
+			// the rejected records are dropped, and the others are accepted
+			dropped := batchSize
+			if partialErr.Rejected < int64(batchSize) {
+				dropped = int(partialErr.Rejected)
+			}
+			return &KeptError{Dropped: dropped, Err: partialErr}
+		}
+	}
+	return nil
+}
+
+// parseOTLPPartialSuccess decode ExportLogsPartialSuccess / ExportTracePartialSuccess
+func parseOTLPPartialSuccess(data []byte) (*OTLPPartialSuccessError, error) {
+	partialErr := &OTLPPartialSuccessError{}
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			var rejected uint64
+			rejected, n = protowire.ConsumeVarint(data)
+			partialErr.Rejected = int64(rejected)
+		case num == 2 && typ == protowire.BytesType:
+			var message []byte
+			message, n = protowire.ConsumeBytes(data)
+			partialErr.Message = string(message)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+	return partialErr, nil
+}
+
+func marshalOTLPLogRecord(record OTLPLogRecord, now time.Time) (b []byte) {
+	if record.ObservedTimestamp.IsZero() {
+		record.ObservedTimestamp = now
+	}
+	b = appendProtoFixed64(b, 1, unixNano(record.Timestamp))
+	b = appendProtoVarint(b, 2, uint64(record.SeverityNumber))
+	b = appendProtoString(b, 3, record.SeverityText)
+	if record.Body != nil {
+		b = appendProtoMessage(b, 5, marshalOTLPAnyValue(record.Body))
+	}
+	b = appendOTLPAttributes(b, 6, record.Attributes)
+	if record.TraceID != [16]byte{} {
+		b = appendProtoBytes(b, 9, record.TraceID[:])
+	}
+	if record.SpanID != [8]byte{} {
+		b = appendProtoBytes(b, 10, record.SpanID[:])
+	}
+	return appendProtoFixed64(b, 11, unixNano(record.ObservedTimestamp))
+}
+
+func marshalOTLPSpan(span OTLPSpan) (b []byte) {
+	b = appendProtoBytes(b, 1, span.TraceID[:])
+	b = appendProtoBytes(b, 2, span.SpanID[:])
+	if span.ParentSpanID != [8]byte{} {
+		b = appendProtoBytes(b, 4, span.ParentSpanID[:])
+	}
+	b = appendProtoString(b, 5, span.Name)
+	b = appendProtoVarint(b, 6, uint64(span.Kind))
+	b = appendProtoFixed64(b, 7, unixNano(span.Start))
+	b = appendProtoFixed64(b, 8, unixNano(span.End))
+	b = appendOTLPAttributes(b, 9, span.Attributes)
+	if span.StatusCode != 0 || span.StatusMessage != "" {
+		var status []byte
+		status = appendProtoString(status, 2, span.StatusMessage)
+		status = appendProtoVarint(status, 3, uint64(span.StatusCode))
+		b = appendProtoMessage(b, 15, status)
+	}
+	return b
+}
+
+// appendOTLPAttributes append attributes as repeated KeyValue field, sorted by key
+func appendOTLPAttributes(b []byte, num protowire.Number, attributes map[string]any) []byte {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var keyValue []byte
+		keyValue = appendProtoString(keyValue, 1, key)
+		keyValue = appendProtoMessage(keyValue, 2, marshalOTLPAnyValue(attributes[key]))
+		b = appendProtoMessage(b, num, keyValue)
+	}
+	return b
+}
+
+// marshalOTLPAnyValue encode a go value as AnyValue, unsupported types are formatted as string.
+// the value is a oneof field, so it's written even if it's zero
+func marshalOTLPAnyValue(value any) (b []byte) {
+	switch v := value.(type) {
+	case string:
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+	case bool:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v))
+	case []byte:
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		b = protowire.AppendBytes(b, v)
+	case []any:
+		var array []byte
+		for _, item := range v {
+			array = appendProtoMessage(array, 1, marshalOTLPAnyValue(item))
+		}
+		b = appendProtoMessage(b, 5, array)
+	case map[string]any:
+		b = appendProtoMessage(b, 6, appendOTLPAttributes(nil, 1, v))
+	default:
+		reflectValue := reflect.ValueOf(value)
+		switch reflectValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			b = protowire.AppendTag(b, 3, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(reflectValue.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if reflectValue.Uint() <= math.MaxInt64 {
+				b = protowire.AppendTag(b, 3, protowire.VarintType)
+				b = protowire.AppendVarint(b, reflectValue.Uint())
+			} else {
+				b = protowire.AppendTag(b, 1, protowire.BytesType)
+				b = protowire.AppendString(b, fmt.Sprint(value))
+			}
+		case reflect.Float32, reflect.Float64:
+			b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+			b = protowire.AppendFixed64(b, math.Float64bits(reflectValue.Float()))
+		default:
+			b = protowire.AppendTag(b, 1, protowire.BytesType)
+			b = protowire.AppendString(b, fmt.Sprint(value))
+		}
+	}
+	return b
+}
+
+func unixNano(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
@@ -0,0 +1,270 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync/atomic"
+)
+
+var (
+	_ Container[struct{ ID int }] = &ParquetContainer[struct{ ID int }]{}
+	_ Keeper                      = &ParquetContainer[struct{ ID int }]{}
+)
+
+// ParquetCodec compression codec of parquet pages, values follow the CompressionCodec enum of parquet.thrift
+//
+//	@author kevineluo
+//	@update 2026-10-18 10:05:31
+type ParquetCodec int32
+
+const (
+	ParquetUncompressed ParquetCodec = 0
+	ParquetSnappy       ParquetCodec = 1
+	ParquetGzip         ParquetCodec = 2
+	ParquetZstd         ParquetCodec = 6
+)
+
+// ParquetConfig ParquetContainer Config
+//
+//	@author kevineluo
+//	@update 2026-10-18 10:05:31
+type ParquetConfig struct {
+	FlushSize        int          // container would be full when holding FlushSize rows
+	RowGroupSize     int          // max rows in one row group, a larger flush would be split into several row groups, default is FlushSize
+	RowGroupsPerFile int          // roll to a new file after writing RowGroupsPerFile row groups, default is 1
+	Codec            ParquetCodec // compression codec of column pages, default is ParquetUncompressed
+	// CreateFile open the destination of the seq-th file(start from 0), the container would close it after writing the footer
+	CreateFile func(seq int) (io.WriteCloser, error)
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *ParquetConfig
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 10:05:31
+func (config *ParquetConfig) Validate() (err error) {
+	if config.FlushSize <= 0 {
+		return fmt.Errorf("[ParquetConfig.Validate] invalid FlushSize: %d, FlushSize should be positive", config.FlushSize)
+	}
+	if config.CreateFile == nil {
+		return errors.New("[ParquetConfig.Validate] CreateFile should not be nil")
+	}
+	if config.RowGroupSize <= 0 {
+		config.RowGroupSize = config.FlushSize
+	}
+	if config.RowGroupsPerFile <= 0 {
+		config.RowGroupsPerFile = 1
+	}
+	switch config.Codec {
+	case ParquetUncompressed, ParquetSnappy, ParquetGzip, ParquetZstd:
+	default:
+		return fmt.Errorf("[ParquetConfig.Validate] unsupported parquet codec: %d", config.Codec)
+	}
+	return
+}
+
+// ParquetContainer write every flush as row group(s) of a parquet file, the schema is derived from struct tags of `T`
+// like `parquet:"name[,timestamp=millis|micros]"`, pointer fields are optional columns.
+// Put can be called while an async Flush is running, flushes run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+type ParquetContainer[T any] struct {
+	config  ParquetConfig
+	batch   batch[T]
+	columns []parquetColumn
+
+	file            io.WriteCloser    // current file, nil when there is no opening file
+	writer          *parquetWriter[T] // writer of current file
+	rowGroupsInFile int               // row groups written into current file
+	rowsInFile      int               // rows written into current file
+	seq             int               // sequence number of next file
+	fileBytes       atomic.Int64      // bytes written into current file, read by Bytes while a Flush is running
+}
+
+// NewParquetContainer new a ParquetContainer
+//
+//	@param config ParquetConfig
+//	@return *ParquetContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 10:05:31
+func NewParquetContainer[T any](config ParquetConfig) (*ParquetContainer[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	columns, err := newParquetSchema(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &ParquetContainer[T]{
+		config:  config,
+		batch:   newBatch[T](config.FlushSize),
+		columns: columns,
+	}, nil
+}
+
+// Put implement interface Container
+//
+//	@receiver container *ParquetContainer[T]
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 10:05:31
+func (container *ParquetContainer[T]) Put(element T) error {
+	container.batch.put(element)
+	return nil
+}
+
+// Flush implement interface Container, write pending rows as row group(s) into current file,
+// the file would be finished when it reach RowGroupsPerFile row groups, and the rest rows go to a new file.
+// when failing to write a row group, current file would be finished with the row groups written before,
+// and rows not written are kept for next flush, which would start a new file.
+// rows of a file failed to be finished are lost, they are counted by Dropped of the returned *KeptError
+//
+//	@receiver container *ParquetContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 12:42:17
+func (container *ParquetContainer[T]) Flush() (err error) {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	if container.batch.len() == 0 {
+		return nil
+	}
+	rows := container.batch.take()
+
+	for start := 0; start < len(rows); start += container.config.RowGroupSize {
+		if container.writer == nil {
+			if err = container.openFile(); err != nil {
+				container.batch.restore(rows[start:])
+				return &KeptError{Err: fmt.Errorf("[ParquetContainer.Flush] %w", err)}
+			}
+		}
+		end := start + container.config.RowGroupSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if err = container.writer.writeRowGroup(rows[start:end]); err != nil {
+			container.batch.restore(rows[start:])
+			err = fmt.Errorf("[ParquetContainer.Flush] fail to write row group: %w", err)
+			lost := container.rowsInFile
+			if finishErr := container.finishFile(); finishErr != nil {
+				return &KeptError{Dropped: lost, Err: errors.Join(err, finishErr)}
+			}
+			return &KeptError{Err: err}
+		}
+		container.rowGroupsInFile++
+		container.rowsInFile += end - start
+		container.fileBytes.Store(container.writer.offset)
+
+		if container.rowGroupsInFile >= container.config.RowGroupsPerFile {
+			lost := container.rowsInFile
+			if err = container.finishFile(); err != nil {
+				container.batch.restore(rows[end:])
+				return &KeptError{Dropped: lost, Err: fmt.Errorf("[ParquetContainer.Flush] %w", err)}
+			}
+		}
+	}
+	return nil
+}
+
+// IsFull implement interface Container
+//
+//	@receiver container *ParquetContainer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 10:05:31
+func (container *ParquetContainer[T]) IsFull() bool {
+	return container.batch.isFull()
+}
+
+// Reset implement interface Container
+//
+//	@receiver container *ParquetContainer[T]
+//	@author kevineluo
+//	@update 2026-10-18 10:05:31
+func (container *ParquetContainer[T]) Reset() {
+	container.batch.reset()
+}
+
+// KeepFailed implement interface Keeper, rows not written are kept for next flush
+//
+//	@receiver container *ParquetContainer[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *ParquetContainer[T]) KeepFailed() {}
+
+// Len return the number of pending rows
+//
+//	@receiver container *ParquetContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 10:05:31
+func (container *ParquetContainer[T]) Len() int {
+	return container.batch.len()
+}
+
+// Bytes return the bytes written into current file, which is not finished by its footer yet
+//
+//	@receiver container *ParquetContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-19 15:34:20
+func (container *ParquetContainer[T]) Bytes() int {
+	return int(container.fileBytes.Load())
+}
+
+// Close finish current file by writing its footer, pending rows which are not flushed would NOT be written
+//
+//	@receiver container *ParquetContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 10:05:31
+func (container *ParquetContainer[T]) Close() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	if container.writer == nil {
+		return nil
+	}
+	return container.finishFile()
+}
+
+func (container *ParquetContainer[T]) openFile() (err error) {
+	if container.file, err = container.config.CreateFile(container.seq); err != nil {
+		return fmt.Errorf("[ParquetContainer.openFile] fail to create file(seq: %d): %w", container.seq, err)
+	}
+	container.seq++
+	if container.writer, err = newParquetWriter[T](container.file, container.columns, container.config.Codec); err != nil {
+		container.abandonFile()
+		return fmt.Errorf("[ParquetContainer.openFile] fail to write file header: %w", err)
+	}
+	container.rowGroupsInFile, container.rowsInFile = 0, 0
+	container.fileBytes.Store(container.writer.offset)
+	return nil
+}
+
+func (container *ParquetContainer[T]) finishFile() error {
+	file := container.file
+	err := container.writer.close()
+	container.file, container.writer = nil, nil
+	container.fileBytes.Store(0)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("[ParquetContainer.finishFile] fail to finish file: %w", err)
+	}
+	return nil
+}
+
+func (container *ParquetContainer[T]) abandonFile() {
+	if container.file != nil {
+		container.file.Close()
+	}
+	container.file, container.writer = nil, nil
+	container.fileBytes.Store(0)
+}
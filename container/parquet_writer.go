@@ -0,0 +1,557 @@
+package container
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// physical types, repetition types, converted types and encodings defined in parquet.thrift
+const (
+	parquetBoolean   int32 = 0
+	parquetInt32     int32 = 1
+	parquetInt64     int32 = 2
+	parquetFloat     int32 = 4
+	parquetDouble    int32 = 5
+	parquetByteArray int32 = 6
+
+	parquetRequired int32 = 0
+	parquetOptional int32 = 1
+
+	parquetConvertedNone            int32 = -1
+	parquetConvertedUTF8            int32 = 0
+	parquetConvertedTimestampMillis int32 = 9
+	parquetConvertedTimestampMicros int32 = 10
+	parquetConvertedUint8           int32 = 11
+	parquetConvertedUint16          int32 = 12
+	parquetConvertedUint32          int32 = 13
+	parquetConvertedUint64          int32 = 14
+	parquetConvertedInt8            int32 = 15
+	parquetConvertedInt16           int32 = 16
+	parquetConvertedInt32           int32 = 17
+
+	parquetEncodingPlain int32 = 0
+	parquetEncodingRLE   int32 = 3
+
+	parquetPageData int32 = 0
+)
+
+// parquetMagic start and end every parquet file
+var parquetMagic = []byte("PAR1")
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// parquetColumn one leaf column of the parquet schema, mapped from a struct field
+//
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+type parquetColumn struct {
+	name          string
+	index         int   // struct field index
+	physicalType  int32 // parquet physical type
+	convertedType int32 // parquet converted type, parquetConvertedNone if absent
+	optional      bool  // pointer field, nil would be written as null
+	kind          reflect.Kind
+	timeUnit      time.Duration // unit of time.Time column, time.Millisecond or time.Microsecond
+}
+
+// newParquetSchema derive parquet columns from the struct tags of `T`
+// the tag format is `parquet:"name[,timestamp=millis|micros]"`, fields tagged with "-" and unexported fields are skipped
+//
+//	@param typ reflect.Type
+//	@return columns []parquetColumn
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+func newParquetSchema(typ reflect.Type) (columns []parquetColumn, err error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("[newParquetSchema] parquet row type should be a struct, got %s", typ)
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("parquet")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		column := parquetColumn{name: name, index: i, convertedType: parquetConvertedNone, timeUnit: time.Millisecond}
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "":
+			case "timestamp=millis":
+				column.timeUnit = time.Millisecond
+			case "timestamp=micros":
+				column.timeUnit = time.Microsecond
+			default:
+				return nil, fmt.Errorf("[newParquetSchema] unknown parquet tag option %q on field %s", option, field.Name)
+			}
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			column.optional = true
+			fieldType = fieldType.Elem()
+		}
+		column.kind = fieldType.Kind()
+		switch {
+		case fieldType == timeType:
+			column.physicalType = parquetInt64
+			column.convertedType = parquetConvertedTimestampMillis
+			if column.timeUnit == time.Microsecond {
+				column.convertedType = parquetConvertedTimestampMicros
+			}
+		case column.kind == reflect.Bool:
+			column.physicalType = parquetBoolean
+		case column.kind == reflect.Int8:
+			column.physicalType, column.convertedType = parquetInt32, parquetConvertedInt8
+		case column.kind == reflect.Int16:
+			column.physicalType, column.convertedType = parquetInt32, parquetConvertedInt16
+		case column.kind == reflect.Int32:
+			column.physicalType, column.convertedType = parquetInt32, parquetConvertedInt32
+		case column.kind == reflect.Int || column.kind == reflect.Int64:
+			column.physicalType = parquetInt64
+		case column.kind == reflect.Uint8:
+			column.physicalType, column.convertedType = parquetInt32, parquetConvertedUint8
+		case column.kind == reflect.Uint16:
+			column.physicalType, column.convertedType = parquetInt32, parquetConvertedUint16
+		case column.kind == reflect.Uint32:
+			column.physicalType, column.convertedType = parquetInt32, parquetConvertedUint32
+		case column.kind == reflect.Uint || column.kind == reflect.Uint64:
+			column.physicalType, column.convertedType = parquetInt64, parquetConvertedUint64
+		case column.kind == reflect.Float32:
+			column.physicalType = parquetFloat
+		case column.kind == reflect.Float64:
+			column.physicalType = parquetDouble
+		case column.kind == reflect.String:
+			column.physicalType, column.convertedType = parquetByteArray, parquetConvertedUTF8
+		case column.kind == reflect.Slice && fieldType.Elem().Kind() == reflect.Uint8:
+			column.physicalType = parquetByteArray
+		default:
+			return nil, fmt.Errorf("[newParquetSchema] unsupported parquet field type %s of field %s", field.Type, field.Name)
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("[newParquetSchema] no parquet column found in %s", typ)
+	}
+	return
+}
+
+// parquetRowGroup metadata of a written row group
+//
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+type parquetRowGroup struct {
+	numRows   int64
+	totalSize int64
+	chunks    []parquetColumnChunk
+}
+
+// parquetColumnChunk metadata of a written column chunk
+//
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+type parquetColumnChunk struct {
+	offset           int64
+	uncompressedSize int64
+	compressedSize   int64
+	numValues        int64
+}
+
+// parquetWriter write rows of `T` into a parquet file row group by row group, footer would be written on close
+//
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+type parquetWriter[T any] struct {
+	writer    io.Writer
+	offset    int64 // bytes written so far
+	columns   []parquetColumn
+	codec     ParquetCodec
+	rowGroups []parquetRowGroup
+	numRows   int64
+}
+
+// newParquetWriter new a parquetWriter and write the leading magic
+//
+//	@param writer io.Writer
+//	@param columns []parquetColumn
+//	@param codec ParquetCodec
+//	@return *parquetWriter[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+func newParquetWriter[T any](writer io.Writer, columns []parquetColumn, codec ParquetCodec) (*parquetWriter[T], error) {
+	pw := &parquetWriter[T]{writer: writer, columns: columns, codec: codec}
+	if err := pw.write(parquetMagic); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (pw *parquetWriter[T]) write(data []byte) error {
+	n, err := pw.writer.Write(data)
+	pw.offset += int64(n)
+	return err
+}
+
+// writeRowGroup write rows as one row group, every column is written as a single PLAIN data page
+//
+//	@receiver pw *parquetWriter[T]
+//	@param rows []T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+func (pw *parquetWriter[T]) writeRowGroup(rows []T) error {
+	rowGroup := parquetRowGroup{numRows: int64(len(rows))}
+	for _, column := range pw.columns {
+		page := encodeParquetPage(column, rows)
+		compressed, err := pw.codec.compress(page)
+		if err != nil {
+			return err
+		}
+
+		header := &thriftWriter{}
+		header.i32(1, parquetPageData)
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(compressed)))
+		header.beginStruct(5)
+		header.i32(1, int32(len(rows)))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.endStruct()
+		header.stop()
+
+		chunk := parquetColumnChunk{
+			offset:           pw.offset,
+			uncompressedSize: int64(header.buf.Len() + len(page)),
+			compressedSize:   int64(header.buf.Len() + len(compressed)),
+			numValues:        int64(len(rows)),
+		}
+		if err := pw.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := pw.write(compressed); err != nil {
+			return err
+		}
+		rowGroup.totalSize += chunk.uncompressedSize
+		rowGroup.chunks = append(rowGroup.chunks, chunk)
+	}
+	pw.rowGroups = append(pw.rowGroups, rowGroup)
+	pw.numRows += rowGroup.numRows
+	return nil
+}
+
+// close write the FileMetaData footer and the trailing magic, it would NOT close the underlying writer
+//
+//	@receiver pw *parquetWriter[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+func (pw *parquetWriter[T]) close() error {
+	footer := &thriftWriter{}
+	footer.i32(1, 1)
+	// schema: the root element followed by every leaf column
+	footer.listBegin(2, thriftStruct, len(pw.columns)+1)
+	footer.structElemBegin()
+	footer.str(4, "schema")
+	footer.i32(5, int32(len(pw.columns)))
+	footer.structElemEnd()
+	for _, column := range pw.columns {
+		footer.structElemBegin()
+		footer.i32(1, column.physicalType)
+		repetition := parquetRequired
+		if column.optional {
+			repetition = parquetOptional
+		}
+		footer.i32(3, repetition)
+		footer.str(4, column.name)
+		if column.convertedType != parquetConvertedNone {
+			footer.i32(6, column.convertedType)
+		}
+		footer.structElemEnd()
+	}
+	footer.i64(3, pw.numRows)
+	footer.listBegin(4, thriftStruct, len(pw.rowGroups))
+	for _, rowGroup := range pw.rowGroups {
+		footer.structElemBegin()
+		footer.listBegin(1, thriftStruct, len(rowGroup.chunks))
+		for i, chunk := range rowGroup.chunks {
+			footer.structElemBegin()
+			footer.i64(2, chunk.offset)
+			footer.beginStruct(3)
+			footer.i32(1, pw.columns[i].physicalType)
+			footer.listBegin(2, thriftI32, 2)
+			footer.listI32(parquetEncodingPlain)
+			footer.listI32(parquetEncodingRLE)
+			footer.listBegin(3, thriftBinary, 1)
+			footer.listStr(pw.columns[i].name)
+			footer.i32(4, int32(pw.codec))
+			footer.i64(5, chunk.numValues)
+			footer.i64(6, chunk.uncompressedSize)
+			footer.i64(7, chunk.compressedSize)
+			footer.i64(9, chunk.offset)
+			footer.endStruct()
+			footer.structElemEnd()
+		}
+		footer.i64(2, rowGroup.totalSize)
+		footer.i64(3, rowGroup.numRows)
+		footer.structElemEnd()
+	}
+	footer.str(6, "github.com/Kevinello/go-buffer")
+	footer.stop()
+
+	if err := pw.write(footer.buf.Bytes()); err != nil {
+		return err
+	}
+	if err := pw.write(binary.LittleEndian.AppendUint32(nil, uint32(footer.buf.Len()))); err != nil {
+		return err
+	}
+	return pw.write(parquetMagic)
+}
+
+// encodeParquetPage encode the values of one column into an uncompressed data page (definition levels + PLAIN values)
+//
+//	@param column parquetColumn
+//	@param rows []T
+//	@return []byte
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+func encodeParquetPage[T any](column parquetColumn, rows []T) []byte {
+	var (
+		page      bytes.Buffer
+		values    bytes.Buffer
+		defLevels = make([]bool, 0, len(rows))
+		bits      []bool
+	)
+	for i := range rows {
+		value := reflect.ValueOf(&rows[i]).Elem().Field(column.index)
+		if column.optional {
+			defLevels = append(defLevels, !value.IsNil())
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+		switch column.physicalType {
+		case parquetBoolean:
+			bits = append(bits, value.Bool())
+		case parquetInt32:
+			if column.kind >= reflect.Uint && column.kind <= reflect.Uint64 {
+				values.Write(binary.LittleEndian.AppendUint32(nil, uint32(value.Uint())))
+			} else {
+				values.Write(binary.LittleEndian.AppendUint32(nil, uint32(value.Int())))
+			}
+		case parquetInt64:
+			switch {
+			case value.Type() == timeType:
+				t := value.Interface().(time.Time)
+				if column.timeUnit == time.Microsecond {
+					values.Write(binary.LittleEndian.AppendUint64(nil, uint64(t.UnixMicro())))
+				} else {
+					values.Write(binary.LittleEndian.AppendUint64(nil, uint64(t.UnixMilli())))
+				}
+			case column.kind >= reflect.Uint && column.kind <= reflect.Uint64:
+				values.Write(binary.LittleEndian.AppendUint64(nil, value.Uint()))
+			default:
+				values.Write(binary.LittleEndian.AppendUint64(nil, uint64(value.Int())))
+			}
+		case parquetFloat:
+			values.Write(binary.LittleEndian.AppendUint32(nil, math.Float32bits(float32(value.Float()))))
+		case parquetDouble:
+			values.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(value.Float())))
+		case parquetByteArray:
+			var data []byte
+			if column.kind == reflect.String {
+				data = []byte(value.String())
+			} else {
+				data = value.Bytes()
+			}
+			values.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(data))))
+			values.Write(data)
+		}
+	}
+	if column.physicalType == parquetBoolean {
+		packed := make([]byte, (len(bits)+7)/8)
+		for i, bit := range bits {
+			if bit {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		values.Write(packed)
+	}
+
+	if column.optional {
+		levels := encodeParquetLevels(defLevels)
+		page.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(levels))))
+		page.Write(levels)
+	}
+	page.Write(values.Bytes())
+	return page.Bytes()
+}
+
+// encodeParquetLevels encode definition levels with max level 1 in the RLE/bit-packing hybrid encoding, using RLE runs only
+//
+//	@param levels []bool
+//	@return []byte
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+func encodeParquetLevels(levels []bool) []byte {
+	var encoded []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		encoded = binary.AppendUvarint(encoded, uint64(j-i)<<1)
+		if levels[i] {
+			encoded = append(encoded, 1)
+		} else {
+			encoded = append(encoded, 0)
+		}
+		i = j
+	}
+	return encoded
+}
+
+// compress compress a page with the codec
+//
+//	@receiver codec ParquetCodec
+//	@param page []byte
+//	@return []byte
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+func (codec ParquetCodec) compress(page []byte) ([]byte, error) {
+	switch codec {
+	case ParquetUncompressed:
+		return page, nil
+	case ParquetSnappy:
+		return snappy.Encode(nil, page), nil
+	case ParquetGzip:
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(page); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case ParquetZstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer encoder.Close()
+		return encoder.EncodeAll(page, nil), nil
+	default:
+		return nil, fmt.Errorf("[ParquetCodec.compress] unsupported parquet codec: %d", codec)
+	}
+}
+
+// element types of the thrift compact protocol
+const (
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftStruct byte = 12
+)
+
+// thriftWriter a minimal thrift compact protocol writer, which is enough for parquet metadata
+//
+//	@author kevineluo
+//	@update 2026-10-18 09:40:12
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastID  int16   // last field id written in current struct
+	idStack []int16 // last field ids of outer structs
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(v<<1)^uint64(v>>63)))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) str(id int16, v string) {
+	t.fieldHeader(id, thriftBinary)
+	t.listStr(v)
+}
+
+func (t *thriftWriter) listBegin(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+	}
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) listStr(v string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(v))))
+	t.buf.WriteString(v)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.structElemBegin()
+}
+
+func (t *thriftWriter) endStruct() {
+	t.structElemEnd()
+}
+
+func (t *thriftWriter) structElemBegin() {
+	t.idStack = append(t.idStack, t.lastID)
+	t.lastID = 0
+}
+
+func (t *thriftWriter) structElemEnd() {
+	t.stop()
+	t.lastID = t.idStack[len(t.idStack)-1]
+	t.idStack = t.idStack[:len(t.idStack)-1]
+}
+
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
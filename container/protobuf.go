@@ -0,0 +1,50 @@
+package container
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// helpers to build protobuf messages without generated code, for containers speaking OTLP and Loki push API.
+// like proto3, scalar fields holding zero value are omitted, embedded messages are always written
+
+// appendProtoMessage append an embedded message field
+func appendProtoMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+// appendProtoBytes append a bytes field
+func appendProtoBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// appendProtoString append a string field
+func appendProtoString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+// appendProtoVarint append a varint field(int32, int64, uint32, uint64, enum), negative value should be converted by uint64(v)
+func appendProtoVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// appendProtoFixed64 append a fixed64 field
+func appendProtoFixed64(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, v)
+}
@@ -0,0 +1,484 @@
+package container
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	_ Container[int] = &RedisContainer[int]{}
+	_ Keeper         = &RedisContainer[int]{}
+)
+
+// RedisCommand the command used to write an element into redis
+//
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+type RedisCommand string
+
+const (
+	RedisXAdd  RedisCommand = "XADD"  // append the element as an entry of stream
+	RedisRPush RedisCommand = "RPUSH" // append the element to the tail of list
+	RedisZAdd  RedisCommand = "ZADD"  // add the element into sorted set
+)
+
+// RedisConfig RedisContainer Config
+//
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+type RedisConfig[T any] struct {
+	ID        string // used as {buffer_id} in KeyTemplate
+	FlushSize int    // container would be full when holding FlushSize elements
+	Addr      string // redis address, e.g. "localhost:6379"
+	Username  string // optional ACL username
+	Password  string // optional password
+	DB        int    // database to SELECT
+	Timeout   time.Duration
+	// Dial optional, open the connection to redis(e.g. with TLS), default dial Addr with TCP
+	Dial func(ctx context.Context) (net.Conn, error)
+
+	Command RedisCommand // default is RedisXAdd
+	// KeyTemplate target key, support placeholders: {date}, {hour}, {minute}, {timestamp}(flush time in UTC) and {buffer_id}
+	KeyTemplate string
+	Key         func(T) string // optional, decide the key of an element, KeyTemplate would be ignored when it's set
+	// Fields optional, convert an element into fields of stream entry, default is one field "data" holding the JSON of element
+	Fields func(T) (map[string]string, error)
+	// Member optional, convert an element into list value or sorted set member, default is the JSON of element
+	Member func(T) (string, error)
+	Score  func(T) float64 // score of sorted set member, required by RedisZAdd
+	// MaxLen trim the stream with `MAXLEN ~ MaxLen` when adding entries, 0 means no trimming
+	MaxLen int64
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *RedisConfig[T]
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+func (config *RedisConfig[T]) Validate() (err error) {
+	if config.FlushSize <= 0 {
+		return fmt.Errorf("[RedisConfig.Validate] invalid FlushSize: %d, FlushSize should be positive", config.FlushSize)
+	}
+	if config.Addr == "" && config.Dial == nil {
+		return errors.New("[RedisConfig.Validate] one of Addr and Dial should be set")
+	}
+	if config.KeyTemplate == "" && config.Key == nil {
+		return errors.New("[RedisConfig.Validate] one of KeyTemplate and Key should be set")
+	}
+	switch config.Command {
+	case "":
+		config.Command = RedisXAdd
+	case RedisXAdd, RedisRPush:
+	case RedisZAdd:
+		if config.Score == nil {
+			return errors.New("[RedisConfig.Validate] Score should not be nil when using ZADD")
+		}
+	default:
+		return fmt.Errorf("[RedisConfig.Validate] unsupported command: %s", config.Command)
+	}
+	if config.MaxLen < 0 {
+		return fmt.Errorf("[RedisConfig.Validate] invalid MaxLen: %d, MaxLen should not be negative", config.MaxLen)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Dial == nil {
+		dialer := &net.Dialer{}
+		config.Dial = func(ctx context.Context) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", config.Addr)
+		}
+	}
+	return
+}
+
+// RedisError an error reply of redis command
+//
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+type RedisError struct {
+	Command string // the failed command with its key, e.g. "XADD events"
+	Message string // error reply, e.g. "WRONGTYPE Operation against a key holding the wrong kind of value"
+}
+
+// Error implement interface error
+func (err *RedisError) Error() string {
+	return fmt.Sprintf("redis command %s failed: %s", err.Command, err.Message)
+}
+
+// RedisContainer flush every batch as one pipeline of XADD, RPUSH or ZADD commands.
+// when the connection is broken, the whole batch would be kept for next Flush;
+// commands failed with error reply would be reported by *PartialFlushError[T].
+// Put can be called while an async Flush is running, flushes run one at a time
+//
+//	@author kevineluo
+//	@update 2026-10-19 14:02:11
+type RedisContainer[T any] struct {
+	config RedisConfig[T]
+	batch  batch[T]
+
+	conn   net.Conn // nil when there is no connection
+	reader *bufio.Reader
+}
+
+// NewRedisContainer new a RedisContainer, the connection would be established on first Flush
+//
+//	@param config RedisConfig[T]
+//	@return *RedisContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+func NewRedisContainer[T any](config RedisConfig[T]) (*RedisContainer[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &RedisContainer[T]{
+		config: config,
+		batch:  newBatch[T](config.FlushSize),
+	}, nil
+}
+
+// Put implement interface Container
+//
+//	@receiver container *RedisContainer[T]
+//	@param element T
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+func (container *RedisContainer[T]) Put(element T) error {
+	container.batch.put(element)
+	return nil
+}
+
+// Flush implement interface Container, send one command per element in a pipeline.
+// when the connection breaks, elements whose commands got no reply are kept for next Flush, reported by *KeptError
+// when no reply is read, or by FailedElement.Requeued of *PartialFlushError
+//
+//	@receiver container *RedisContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 15:58:14
+func (container *RedisContainer[T]) Flush() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	if container.batch.len() == 0 {
+		return nil
+	}
+	elements := container.batch.take()
+
+	commands, err := container.commands(elements, time.Now())
+	if err != nil {
+		return fmt.Errorf("[RedisContainer.Flush] fail to build commands: %w", err)
+	}
+	replies, err := container.pipeline(commands)
+	if err != nil {
+		container.closeConn()
+		// commands replied before the break are executed, only the others are sent again
+		container.batch.restore(elements[len(replies):])
+		err = fmt.Errorf("[RedisContainer.Flush] fail to execute pipeline: %w", err)
+		if len(replies) == 0 {
+			return &KeptError{Err: err}
+		}
+	}
+
+	partialErr := &PartialFlushError[T]{Total: len(elements)}
+	for i, reply := range replies {
+		if reply != nil {
+			partialErr.Failed = append(partialErr.Failed, FailedElement[T]{
+				Element: elements[i],
+				Err:     &RedisError{Command: commands[i][0] + " " + commands[i][1], Message: reply.Error()},
+			})
+		}
+	}
+	if err != nil {
+		for _, element := range elements[len(replies):] {
+			partialErr.Failed = append(partialErr.Failed, FailedElement[T]{Element: element, Err: err, Requeued: true})
+		}
+	}
+	if len(partialErr.Failed) > 0 {
+		return partialErr
+	}
+	return nil
+}
+
+// IsFull implement interface Container
+//
+//	@receiver container *RedisContainer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+func (container *RedisContainer[T]) IsFull() bool {
+	return container.batch.isFull()
+}
+
+// Reset implement interface Container
+//
+//	@receiver container *RedisContainer[T]
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+func (container *RedisContainer[T]) Reset() {
+	container.batch.reset()
+}
+
+// KeepFailed implement interface Keeper, a batch failed with broken connection is kept in the container
+//
+//	@receiver container *RedisContainer[T]
+//	@author kevineluo
+//	@update 2026-10-19 15:52:30
+func (container *RedisContainer[T]) KeepFailed() {}
+
+// Len return the number of pending elements
+//
+//	@receiver container *RedisContainer[T]
+//	@return int
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+func (container *RedisContainer[T]) Len() int {
+	return container.batch.len()
+}
+
+// Close close the connection to redis
+//
+//	@receiver container *RedisContainer[T]
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+func (container *RedisContainer[T]) Close() error {
+	container.batch.flushing.Lock()
+	defer container.batch.flushing.Unlock()
+	return container.closeConn()
+}
+
+// closeConn close the connection, the caller should hold batch.flushing
+func (container *RedisContainer[T]) closeConn() error {
+	if container.conn == nil {
+		return nil
+	}
+	err := container.conn.Close()
+	container.conn, container.reader = nil, nil
+	return err
+}
+
+// commands build one command per element
+//
+//	@receiver container *RedisContainer[T]
+//	@param elements []T
+//	@param now time.Time
+//	@return commands [][]string
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+func (container *RedisContainer[T]) commands(elements []T, now time.Time) (commands [][]string, err error) {
+	var key string
+	if container.config.Key == nil {
+		values := timeTemplateValues(now)
+		values["buffer_id"] = container.config.ID
+		key = expandTemplate(container.config.KeyTemplate, values)
+	}
+
+	commands = make([][]string, 0, len(elements))
+	for _, element := range elements {
+		elementKey := key
+		if container.config.Key != nil {
+			elementKey = container.config.Key(element)
+		}
+		command := []string{string(container.config.Command), elementKey}
+		switch container.config.Command {
+		case RedisXAdd:
+			if container.config.MaxLen > 0 {
+				command = append(command, "MAXLEN", "~", strconv.FormatInt(container.config.MaxLen, 10))
+			}
+			command = append(command, "*")
+			fields, err := container.fields(element)
+			if err != nil {
+				return nil, err
+			}
+			command = append(command, fields...)
+		case RedisRPush:
+			member, err := container.member(element)
+			if err != nil {
+				return nil, err
+			}
+			command = append(command, member)
+		case RedisZAdd:
+			member, err := container.member(element)
+			if err != nil {
+				return nil, err
+			}
+			command = append(command, strconv.FormatFloat(container.config.Score(element), 'g', -1, 64), member)
+		}
+		commands = append(commands, command)
+	}
+	return
+}
+
+func (container *RedisContainer[T]) fields(element T) ([]string, error) {
+	if container.config.Fields == nil {
+		data, err := json.Marshal(element)
+		return []string{"data", string(data)}, err
+	}
+	fields, err := container.config.Fields(element)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(fields)*2)
+	for _, name := range names {
+		pairs = append(pairs, name, fields[name])
+	}
+	return pairs, nil
+}
+
+func (container *RedisContainer[T]) member(element T) (string, error) {
+	if container.config.Member == nil {
+		data, err := json.Marshal(element)
+		return string(data), err
+	}
+	return container.config.Member(element)
+}
+
+// pipeline send all commands and then read their replies, error replies are returned per command,
+// the returned error means the connection is broken, and replyErrs only hold the replies read before the break
+//
+//	@receiver container *RedisContainer[T]
+//	@param commands [][]string
+//	@return replyErrs []error nil for successful command
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-19 15:58:14
+func (container *RedisContainer[T]) pipeline(commands [][]string) (replyErrs []error, err error) {
+	if err = container.connect(); err != nil {
+		return
+	}
+	container.conn.SetDeadline(time.Now().Add(container.config.Timeout))
+
+	writer := bufio.NewWriter(container.conn)
+	for _, command := range commands {
+		writeRESPCommand(writer, command)
+	}
+	if err = writer.Flush(); err != nil {
+		return
+	}
+	replyErrs = make([]error, 0, len(commands))
+	for range commands {
+		var replyErr error
+		if replyErr, err = readRESPReply(container.reader); err != nil {
+			return
+		}
+		replyErrs = append(replyErrs, replyErr)
+	}
+	return
+}
+
+// connect dial redis and authenticate if there is no connection
+func (container *RedisContainer[T]) connect() (err error) {
+	if container.conn != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), container.config.Timeout)
+	defer cancel()
+	if container.conn, err = container.config.Dial(ctx); err != nil {
+		return
+	}
+	container.reader = bufio.NewReader(container.conn)
+
+	setup := make([][]string, 0, 2)
+	if container.config.Password != "" {
+		if container.config.Username != "" {
+			setup = append(setup, []string{"AUTH", container.config.Username, container.config.Password})
+		} else {
+			setup = append(setup, []string{"AUTH", container.config.Password})
+		}
+	}
+	if container.config.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(container.config.DB)})
+	}
+	if len(setup) == 0 {
+		return nil
+	}
+	container.conn.SetDeadline(time.Now().Add(container.config.Timeout))
+	writer := bufio.NewWriter(container.conn)
+	for _, command := range setup {
+		writeRESPCommand(writer, command)
+	}
+	if err = writer.Flush(); err == nil {
+		for _, command := range setup {
+			var replyErr error
+			if replyErr, err = readRESPReply(container.reader); err != nil {
+				break
+			}
+			if replyErr != nil {
+				err = fmt.Errorf("%s failed: %w", command[0], replyErr)
+				break
+			}
+		}
+	}
+	if err != nil {
+		container.closeConn()
+	}
+	return
+}
+
+// writeRESPCommand write a command as RESP array of bulk strings
+func writeRESPCommand(writer *bufio.Writer, command []string) {
+	writer.WriteString("*" + strconv.Itoa(len(command)) + "\r\n")
+	for _, arg := range command {
+		writer.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+}
+
+// readRESPReply read and discard a reply, return the error reply as replyErr
+//
+//	@param reader *bufio.Reader
+//	@return replyErr error
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 15:03:44
+func readRESPReply(reader *bufio.Reader) (replyErr error, err error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("invalid RESP line: %q", line)
+	}
+	payload := line[1 : len(line)-2]
+	switch line[0] {
+	case '+', ':':
+		return nil, nil
+	case '-':
+		return errors.New(payload), nil
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		_, err = io.CopyN(io.Discard, reader, int64(size)+2)
+		return nil, err
+	case '*':
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < count; i++ {
+			if _, err = readRESPReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported RESP type: %q", line[0])
+	}
+}
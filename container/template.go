@@ -0,0 +1,41 @@
+package container
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// expandTemplate replace every `{name}` placeholder in template with values[name], unknown placeholders are kept as they are
+//
+//	@param template string
+//	@param values map[string]string
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-18 11:02:17
+func expandTemplate(template string, values map[string]string) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+	oldnew := make([]string, 0, len(values)*2)
+	for name, value := range values {
+		oldnew = append(oldnew, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(oldnew...).Replace(template)
+}
+
+// timeTemplateValues return the time related placeholder values: {date}, {hour}, {minute} and {timestamp}, in UTC
+//
+//	@param now time.Time
+//	@return map[string]string
+//	@author kevineluo
+//	@update 2026-10-18 11:02:17
+func timeTemplateValues(now time.Time) map[string]string {
+	now = now.UTC()
+	return map[string]string{
+		"date":      now.Format(time.DateOnly),
+		"hour":      now.Format("15"),
+		"minute":    now.Format("04"),
+		"timestamp": strconv.FormatInt(now.Unix(), 10),
+	}
+}
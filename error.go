@@ -1,8 +1,49 @@
 package buffer
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrClosed indicates the buffer is closed and can no longer be used.
 	ErrClosed = errors.New("buffer is closed")
+	// ErrDuplicateID indicates another buffer with the same Config.ID is registered in the Registry.
+	ErrDuplicateID = errors.New("buffer ID is already registered")
 )
+
+// FlushError error of a buffer with the context of the failed batch, delivered to Config.ErrorHandler and the error channel
+//
+//	@author kevineluo
+//	@update 2026-10-18 21:26:30
+type FlushError struct {
+	ID        string       // Config.ID of the buffer
+	Trigger   FlushTrigger // what triggers the flush, empty for errors of Container.Put
+	BatchSize int          // data held by container before the flush, -1 if the container doesn't implement `Len() int`
+	Attempt   int          // times Container.Flush has been called for the batch, including retries
+	Records   any          // []T of failed records when they are known, e.g. the container returns *container.PartialFlushError[T]
+	Err       error        // the underlying error
+}
+
+// Error implement interface error
+//
+//	@receiver err *FlushError
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-18 21:26:30
+func (err *FlushError) Error() string {
+	if err.Trigger == "" {
+		return fmt.Sprintf("[buffer %s] put failed: %v", err.ID, err.Err)
+	}
+	return fmt.Sprintf("[buffer %s] %s flush of %d records failed after %d attempts: %v", err.ID, err.Trigger, err.BatchSize, err.Attempt, err.Err)
+}
+
+// Unwrap return the underlying error
+//
+//	@receiver err *FlushError
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 21:26:30
+func (err *FlushError) Unwrap() error {
+	return err.Err
+}
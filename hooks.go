@@ -0,0 +1,43 @@
+package buffer
+
+import "time"
+
+// FlushInfo information of a flush passed to Hooks
+//
+//	@author kevineluo
+//	@update 2026-10-18 21:12:48
+type FlushInfo struct {
+	ID        string        // Config.ID of the buffer
+	Trigger   FlushTrigger  // what triggers the flush
+	BatchSize int           // data held by container before the flush, -1 if the container doesn't implement `Len() int`
+	Retries   int           // retries done so far
+	Duration  time.Duration // latency of the flush including retries, only set for OnFlushDone
+	Err       error         // error of the flush, only set for OnRetry and OnFlushDone
+}
+
+// Hooks callbacks of buffer lifecycle, every field is optional. they are called synchronously by goroutines of the buffer,
+// so they should return quickly and be thread safe, since async flushes run concurrently
+//
+//	@author kevineluo
+//	@update 2026-10-18 21:12:48
+type Hooks struct {
+	OnFlushStart func(info FlushInfo)                      // before the first call of Container.Flush
+	OnFlushDone  func(info FlushInfo)                      // after the flush succeeds or runs out of retries
+	OnRetry      func(info FlushInfo)                      // before retrying a failed flush, info.Err is the error of the last attempt
+	OnDrop       func(id string, reason string, count int) // when data is dropped, see DropReason...
+	OnClose      func(id string, err error)                // after the buffer is closed and drained, err is returned by Shutdown too
+}
+
+// drop record dropped data in metrics and hooks
+//
+//	@receiver buffer *Buffer[T]
+//	@param reason string
+//	@param count int
+//	@author kevineluo
+//	@update 2026-10-18 21:12:48
+func (buffer *Buffer[T]) drop(reason string, count int) {
+	buffer.Metrics.ObserveDrop(buffer.ID, reason, count)
+	if buffer.Hooks.OnDrop != nil {
+		buffer.Hooks.OnDrop(buffer.ID, reason, count)
+	}
+}
@@ -0,0 +1,505 @@
+// Package chgen generate zero-reflection ClickHouse column code for ClickHouseContainer,
+// from a tagged Go struct or the output of `DESCRIBE TABLE ... FORMAT TSV`
+//
+//	@update 2026-10-18 18:10:37
+package chgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// baseType a supported ClickHouse base type
+type baseType struct {
+	goType string // Go type of the value, e.g. "int64"
+	column string // expression of a new column, e.g. "new(proto.ColInt64)", %d is replaced by precision of DateTime64
+}
+
+var baseTypes = map[string]baseType{
+	"String":     {"string", "new(proto.ColStr)"},
+	"Bool":       {"bool", "new(proto.ColBool)"},
+	"Int8":       {"int8", "new(proto.ColInt8)"},
+	"Int16":      {"int16", "new(proto.ColInt16)"},
+	"Int32":      {"int32", "new(proto.ColInt32)"},
+	"Int64":      {"int64", "new(proto.ColInt64)"},
+	"UInt8":      {"uint8", "new(proto.ColUInt8)"},
+	"UInt16":     {"uint16", "new(proto.ColUInt16)"},
+	"UInt32":     {"uint32", "new(proto.ColUInt32)"},
+	"UInt64":     {"uint64", "new(proto.ColUInt64)"},
+	"Float32":    {"float32", "new(proto.ColFloat32)"},
+	"Float64":    {"float64", "new(proto.ColFloat64)"},
+	"DateTime":   {"time.Time", "new(proto.ColDateTime)"},
+	"DateTime64": {"time.Time", "new(proto.ColDateTime64).WithPrecision(%d)"},
+	"UUID":       {"uuid.UUID", "new(proto.ColUUID)"},
+}
+
+// goBaseTypes map Go types of struct fields into ClickHouse base types, int and uint are converted into int64 and uint64
+var goBaseTypes = map[string]string{
+	"string":    "String",
+	"bool":      "Bool",
+	"int8":      "Int8",
+	"int16":     "Int16",
+	"int32":     "Int32",
+	"int64":     "Int64",
+	"int":       "Int64",
+	"uint8":     "UInt8",
+	"uint16":    "UInt16",
+	"uint32":    "UInt32",
+	"uint64":    "UInt64",
+	"uint":      "UInt64",
+	"float32":   "Float32",
+	"float64":   "Float64",
+	"time.Time": "DateTime",
+	"uuid.UUID": "UUID",
+}
+
+var dateTime64Pattern = regexp.MustCompile(`^DateTime64\((\d)(,\s*'[^']*')?\)$`)
+
+// Column a column to generate code for
+//
+//	@author kevineluo
+//	@update 2026-10-18 18:10:37
+type Column struct {
+	Name           string // column name
+	Field          string // Go field name
+	FieldType      string // Go type of the field element, e.g. "int" in []int, differs from the base type only for int and uint
+	Base           string // ClickHouse base type, e.g. "String"
+	Precision      int    // precision of DateTime64
+	LowCardinality bool   // LowCardinality(Base)
+	Nullable       bool   // Nullable(Base), the field is a pointer
+	Array          bool   // Array(Base) or Array(LowCardinality(Base)), the field is a slice
+}
+
+// Type return the ClickHouse type of the column
+//
+//	@receiver column Column
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-18 18:10:37
+func (column Column) Type() string {
+	typ := column.Base
+	if column.Base == "DateTime64" {
+		typ = fmt.Sprintf("DateTime64(%d)", column.Precision)
+	}
+	if column.LowCardinality {
+		typ = "LowCardinality(" + typ + ")"
+	}
+	if column.Nullable {
+		typ = "Nullable(" + typ + ")"
+	}
+	if column.Array {
+		typ = "Array(" + typ + ")"
+	}
+	return typ
+}
+
+// Table the row type and its columns
+//
+//	@author kevineluo
+//	@update 2026-10-18 18:10:37
+type Table struct {
+	Package      string   // package of generated file
+	Type         string   // name of the row type
+	DefineStruct bool     // define the row type in generated file, used when columns come from DESCRIBE TABLE
+	Columns      []Column // columns in insert order
+}
+
+// ParseStruct parse columns of the struct typeName in Go source, with the same tag rule as container.ClickHouseMapper:
+// `ch:"name[,lowcardinality][,datetime64=precision]"`, name defaults to field name, fields tagged with "-" and unexported fields are skipped
+//
+//	@param filename string used in error messages
+//	@param src []byte
+//	@param typeName string
+//	@return table Table
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 18:10:37
+func ParseStruct(filename string, src []byte, typeName string) (table Table, err error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return
+	}
+	table = Table{Package: file.Name.Name, Type: typeName}
+
+	var structType *ast.StructType
+	ast.Inspect(file, func(node ast.Node) bool {
+		if spec, ok := node.(*ast.TypeSpec); ok && spec.Name.Name == typeName {
+			structType, _ = spec.Type.(*ast.StructType)
+			return false
+		}
+		return structType == nil
+	})
+	if structType == nil {
+		return table, fmt.Errorf("struct %s is not found in %s", typeName, filename)
+	}
+
+	for _, field := range structType.Fields.List {
+		var tag string
+		if field.Tag != nil {
+			value, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(value).Get("ch")
+		}
+		if tag == "-" {
+			continue
+		}
+		if len(field.Names) == 0 {
+			return table, fmt.Errorf("embedded field %s is not supported", exprString(field.Type))
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			column, err := parseField(name.Name, field.Type, tag)
+			if err != nil {
+				return table, fmt.Errorf("field %s: %w", name.Name, err)
+			}
+			table.Columns = append(table.Columns, column)
+		}
+	}
+	if len(table.Columns) == 0 {
+		return table, fmt.Errorf("struct %s has no column", typeName)
+	}
+	return
+}
+
+// parseField parse a struct field into column
+func parseField(fieldName string, expr ast.Expr, tag string) (column Column, err error) {
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = fieldName
+	}
+	column = Column{Name: name, Field: fieldName, Precision: -1}
+
+	switch typ := expr.(type) {
+	case *ast.StarExpr:
+		column.Nullable, expr = true, typ.X
+	case *ast.ArrayType:
+		if typ.Len != nil {
+			return column, fmt.Errorf("array type %s is not supported, use slice instead", exprString(expr))
+		}
+		column.Array, expr = true, typ.Elt
+	}
+	column.FieldType = exprString(expr)
+	if column.Base = goBaseTypes[column.FieldType]; column.Base == "" {
+		return column, fmt.Errorf("unsupported type %s", exprString(expr))
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		switch {
+		case option == "":
+		case option == "lowcardinality":
+			column.LowCardinality = true
+		case strings.HasPrefix(option, "datetime64="):
+			precision, err := strconv.Atoi(strings.TrimPrefix(option, "datetime64="))
+			if err != nil || precision < 0 || precision > 9 {
+				return column, fmt.Errorf("invalid option %s, precision should be in [0, 9]", option)
+			}
+			if column.Base != "DateTime" {
+				return column, fmt.Errorf("option %s is only supported by time.Time", option)
+			}
+			column.Base, column.Precision = "DateTime64", precision
+		default:
+			return column, fmt.Errorf("unsupported option %s", option)
+		}
+	}
+	if column.LowCardinality && column.Nullable {
+		return column, fmt.Errorf("option lowcardinality is not supported by Nullable")
+	}
+	return
+}
+
+// ParseDescribe parse columns from the output of `DESCRIBE TABLE ... FORMAT TSV`(or TSVWithNames),
+// MATERIALIZED, ALIAS and EPHEMERAL columns and columns in exclude are skipped since they can't or needn't be inserted
+//
+//	@param reader io.Reader
+//	@param typeName string
+//	@param exclude []string
+//	@return table Table
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 18:10:37
+func ParseDescribe(reader io.Reader, typeName string, exclude []string) (table Table, err error) {
+	table = Table{Type: typeName, DefineStruct: true}
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 2 || (line == 1 && fields[0] == "name" && fields[1] == "type") {
+			continue
+		}
+		name, typ := unescapeTSV(fields[0]), unescapeTSV(fields[1])
+		if len(fields) > 2 && (fields[2] == "MATERIALIZED" || fields[2] == "ALIAS" || fields[2] == "EPHEMERAL") || excluded[name] {
+			continue
+		}
+		column, err := parseColumnType(name, typ)
+		if err != nil {
+			return table, fmt.Errorf("line %d: column %s: %w", line, name, err)
+		}
+		table.Columns = append(table.Columns, column)
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if len(table.Columns) == 0 {
+		return table, fmt.Errorf("no column is found")
+	}
+	return
+}
+
+// parseColumnType parse a ClickHouse type into column
+func parseColumnType(name, typ string) (column Column, err error) {
+	column = Column{Name: name, Field: fieldName(name), Precision: -1}
+	original := typ
+	if inner, ok := unwrapType(typ, "Array"); ok {
+		column.Array, typ = true, inner
+	}
+	if inner, ok := unwrapType(typ, "Nullable"); ok && !column.Array {
+		column.Nullable, typ = true, inner
+	}
+	if inner, ok := unwrapType(typ, "LowCardinality"); ok {
+		column.LowCardinality, typ = true, inner
+	}
+	if _, ok := unwrapType(typ, "Nullable"); ok && column.LowCardinality {
+		// ColLowCardinality of ch-go has no nullable dictionary
+		return column, fmt.Errorf("unsupported type %s, LowCardinality(Nullable(X)) can't be inserted by ch-go, exclude the column or change it to LowCardinality(X)", original)
+	}
+
+	switch {
+	case strings.HasPrefix(typ, "DateTime64("):
+		match := dateTime64Pattern.FindStringSubmatch(typ)
+		if match == nil {
+			return column, fmt.Errorf("unsupported type %s", original)
+		}
+		column.Base, column.Precision = "DateTime64", int(match[1][0]-'0')
+	case strings.HasPrefix(typ, "DateTime("):
+		column.Base = "DateTime"
+	default:
+		if _, ok := baseTypes[typ]; !ok {
+			return column, fmt.Errorf("unsupported type %s", original)
+		}
+		column.Base = typ
+	}
+	column.FieldType = baseTypes[column.Base].goType
+	return
+}
+
+// unwrapType return "X" of "wrapper(X)"
+func unwrapType(typ, wrapper string) (string, bool) {
+	if strings.HasPrefix(typ, wrapper+"(") && strings.HasSuffix(typ, ")") {
+		return typ[len(wrapper)+1 : len(typ)-1], true
+	}
+	return typ, false
+}
+
+// Generate generate the Go source of NewXxxInput and Xxx.Insert, and the struct Xxx if DefineStruct is set
+//
+//	@param table Table
+//	@return []byte formatted source
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 18:10:37
+func Generate(table Table) ([]byte, error) {
+	var buf bytes.Buffer
+	imports := map[string]bool{"fmt": true, "github.com/ClickHouse/ch-go/proto": true}
+	for _, column := range table.Columns {
+		// time and uuid are referred by the struct definition and type parameters of generic columns
+		if !table.DefineStruct && !column.Nullable && !column.Array && !column.LowCardinality {
+			continue
+		}
+		switch column.FieldType {
+		case "time.Time":
+			imports["time"] = true
+		case "uuid.UUID":
+			imports["github.com/google/uuid"] = true
+		}
+	}
+
+	fmt.Fprintf(&buf, "// Code generated by go-buffer-gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", table.Package)
+	for _, path := range []string{"fmt", "time", "", "github.com/ClickHouse/ch-go/proto", "github.com/google/uuid"} {
+		if path == "" {
+			// separate standard library and third-party packages
+			buf.WriteString("\n")
+		} else if imports[path] {
+			fmt.Fprintf(&buf, "%q\n", path)
+		}
+	}
+	buf.WriteString(")\n\n")
+
+	if table.DefineStruct {
+		fmt.Fprintf(&buf, "// %s a row of ClickHouse table\ntype %s struct {\n", table.Type, table.Type)
+		for _, column := range table.Columns {
+			fmt.Fprintf(&buf, "%s %s `ch:\"%s\"`\n", column.Field, fieldType(column), tagOf(column))
+		}
+		buf.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(&buf, "// New%sInput new the proto.Input of %s, used as newInputFunc of ClickHouseContainer\n", table.Type, table.Type)
+	fmt.Fprintf(&buf, "func New%sInput() proto.Input {\nreturn proto.Input{\n", table.Type)
+	for _, column := range table.Columns {
+		fmt.Fprintf(&buf, "{Name: %q, Data: %s},\n", column.Name, newColumnExpr(column))
+	}
+	buf.WriteString("}\n}\n\n")
+
+	fmt.Fprintf(&buf, "// Insert implement container.ClickHouseRow, input should be created by New%sInput\n", table.Type)
+	fmt.Fprintf(&buf, "func (row %s) Insert(input proto.Input) error {\n", table.Type)
+	fmt.Fprintf(&buf, "if len(input) != %d {\nreturn fmt.Errorf(\"input has %%d columns, but %s has %d\", len(input))\n}\n", len(table.Columns), table.Type, len(table.Columns))
+	for i, column := range table.Columns {
+		writeAppend(&buf, i, column)
+	}
+	buf.WriteString("return nil\n}\n")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("fail to format generated code: %w\n%s", err, buf.String())
+	}
+	return source, nil
+}
+
+// valueType return the element type held by the column, e.g. "string" for LowCardinality(String)
+func valueType(column Column) string {
+	return baseTypes[column.Base].goType
+}
+
+// baseColumnExpr return the expression of a new base column, wrapped by LowCardinality if necessary
+func baseColumnExpr(column Column) string {
+	expr := baseTypes[column.Base].column
+	if column.Base == "DateTime64" {
+		expr = fmt.Sprintf(expr, column.Precision)
+	}
+	if column.LowCardinality {
+		expr = fmt.Sprintf("proto.NewLowCardinality[%s](%s)", valueType(column), expr)
+	}
+	return expr
+}
+
+func newColumnExpr(column Column) string {
+	switch {
+	case column.Nullable:
+		return fmt.Sprintf("proto.NewColNullable[%s](%s)", valueType(column), baseColumnExpr(column))
+	case column.Array:
+		return fmt.Sprintf("proto.NewArray[%s](%s)", valueType(column), baseColumnExpr(column))
+	default:
+		return baseColumnExpr(column)
+	}
+}
+
+// columnGoType return the Go type of the column, e.g. "*proto.ColArr[string]"
+func columnGoType(column Column) string {
+	switch {
+	case column.Nullable:
+		return fmt.Sprintf("*proto.ColNullable[%s]", valueType(column))
+	case column.Array:
+		return fmt.Sprintf("*proto.ColArr[%s]", valueType(column))
+	case column.LowCardinality:
+		return fmt.Sprintf("*proto.ColLowCardinality[%s]", valueType(column))
+	default:
+		// e.g. new(proto.ColDateTime64).WithPrecision(3) -> *proto.ColDateTime64
+		expr := baseTypes[column.Base].column
+		return "*" + expr[len("new("):strings.IndexByte(expr, ')')]
+	}
+}
+
+func writeAppend(buf *bytes.Buffer, index int, column Column) {
+	value := "row." + column.Field
+	convert := column.FieldType != valueType(column)
+	switch {
+	case column.Nullable:
+		elem := "*" + value
+		if convert {
+			elem = fmt.Sprintf("%s(%s)", valueType(column), elem)
+		}
+		fmt.Fprintf(buf, "if %s == nil {\ninput[%d].Data.(%s).Append(proto.Null[%s]())\n} else {\ninput[%d].Data.(%s).Append(proto.NewNullable(%s))\n}\n",
+			value, index, columnGoType(column), valueType(column), index, columnGoType(column), elem)
+	case column.Array && convert:
+		fmt.Fprintf(buf, "{\nvalues := make([]%s, len(%s))\nfor i, v := range %s {\nvalues[i] = %s(v)\n}\ninput[%d].Data.(%s).Append(values)\n}\n",
+			valueType(column), value, value, valueType(column), index, columnGoType(column))
+	default:
+		if convert {
+			value = fmt.Sprintf("%s(%s)", valueType(column), value)
+		}
+		fmt.Fprintf(buf, "input[%d].Data.(%s).Append(%s)\n", index, columnGoType(column), value)
+	}
+}
+
+// fieldType return the Go type of a struct field defined by generated code
+func fieldType(column Column) string {
+	switch {
+	case column.Nullable:
+		return "*" + column.FieldType
+	case column.Array:
+		return "[]" + column.FieldType
+	default:
+		return column.FieldType
+	}
+}
+
+// tagOf return the `ch` tag of a struct field defined by generated code
+func tagOf(column Column) string {
+	tag := column.Name
+	if column.LowCardinality {
+		tag += ",lowcardinality"
+	}
+	if column.Base == "DateTime64" {
+		tag += ",datetime64=" + strconv.Itoa(column.Precision)
+	}
+	return tag
+}
+
+// fieldName convert a column name into an exported Go field name, e.g. "trace_id" -> "TraceID"
+func fieldName(column string) string {
+	var builder strings.Builder
+	for _, part := range strings.FieldsFunc(column, func(r rune) bool { return !isIdentRune(r) }) {
+		if upper := strings.ToUpper(part); initialisms[upper] {
+			builder.WriteString(upper)
+			continue
+		}
+		builder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	name := builder.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "C" + name
+	}
+	return name
+}
+
+var initialisms = map[string]bool{"ID": true, "URL": true, "URI": true, "IP": true, "UUID": true, "HTTP": true, "JSON": true, "API": true}
+
+func isIdentRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// unescapeTSV unescape a field of TSV format
+func unescapeTSV(field string) string {
+	return strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\\`, `\`, `\'`, "'").Replace(field)
+}
+
+func exprString(expr ast.Expr) string {
+	switch typ := expr.(type) {
+	case *ast.Ident:
+		return typ.Name
+	case *ast.SelectorExpr:
+		return exprString(typ.X) + "." + typ.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(typ.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(typ.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(typ.Key) + "]" + exprString(typ.Value)
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
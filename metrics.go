@@ -0,0 +1,89 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// FlushTrigger what triggers a flush of Buffer
+type FlushTrigger string
+
+const (
+	FlushTriggerFull   FlushTrigger = "full"   // container is full
+	FlushTriggerTicker FlushTrigger = "ticker" // Config.FlushInterval elapsed
+	FlushTriggerManual FlushTrigger = "manual" // Buffer.Flush is called
+	FlushTriggerClose  FlushTrigger = "close"  // last flush when buffer is closed
+)
+
+// reasons of dropped data
+const (
+	DropReasonClosed      = "closed"       // Buffer.Put after buffer is closed
+	DropReasonPutFailed   = "put_failed"   // Container.Put returns error
+	DropReasonFlushFailed = "flush_failed" // data failed in Container.Flush and not kept by container for next Flush
+)
+
+// Metrics record the state of Buffer, implementations should be thread safe, since they are called by
+// the goroutine of Buffer.Put and background goroutines of Buffer. one Metrics can be shared by multiple buffers,
+// which are distinguished by Config.ID
+//
+//	@author kevineluo
+//	@update 2026-10-19 16:10:21
+type Metrics interface {
+	// ObservePut record a piece of data put into buffer
+	ObservePut(id string)
+	// ObserveDrop record count pieces of data dropped by reason, see DropReason...
+	ObserveDrop(id string, reason string, count int)
+	// SetQueueDepth record the number of data waiting in the channel of buffer
+	SetQueueDepth(id string, depth int)
+	// SetContainerSize record the number of data held by container, only for containers implement `Len() int`
+	SetContainerSize(id string, size int)
+	// ObserveFlush record a flush with its batch size(-1 if the container doesn't implement `Len() int`),
+	// latency including retries and the final error, err is nil when the flush succeeds
+	ObserveFlush(id string, trigger FlushTrigger, batchSize int, latency time.Duration, err error)
+	// ObserveRetry record a retry of failed flush
+	ObserveRetry(id string, trigger FlushTrigger)
+	// Forget remove everything recorded for the buffer, called after the buffer is closed,
+	// so buffers created and closed on the fly don't leave their series behind
+	Forget(id string)
+}
+
+// nopMetrics default Metrics which records nothing
+type nopMetrics struct{}
+
+func (nopMetrics) ObservePut(string)                                            {}
+func (nopMetrics) ObserveDrop(string, string, int)                              {}
+func (nopMetrics) SetQueueDepth(string, int)                                    {}
+func (nopMetrics) SetContainerSize(string, int)                                 {}
+func (nopMetrics) ObserveFlush(string, FlushTrigger, int, time.Duration, error) {}
+func (nopMetrics) ObserveRetry(string, FlushTrigger)                            {}
+func (nopMetrics) Forget(string)                                                {}
+
+// FlushErrorReason classify error of Container.Flush into a low cardinality reason for metrics:
+// "timeout", "canceled", "partial"(container.PartialFlushError), "retryable"(error with `Retryable() bool` returning true) or "error"
+//
+//	@param err error
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-18 19:28:40
+func FlushErrorReason(err error) string {
+	var retryable interface{ Retryable() bool }
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case isPartialFlushError(err):
+		return "partial"
+	case errors.As(err, &retryable) && retryable.Retryable():
+		return "retryable"
+	default:
+		return "error"
+	}
+}
+
+// isPartialFlushError check if err is a *container.PartialFlushError of any element type
+func isPartialFlushError(err error) bool {
+	var partial interface{ Requeued() int }
+	return errors.As(err, &partial)
+}
@@ -0,0 +1,272 @@
+package buffer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName name of tracer and meter of go-buffer
+const InstrumentationName = "github.com/Kevinello/go-buffer"
+
+// maxFlushLinks max number of put-side spans linked to one flush span, more links are dropped
+const maxFlushLinks = 128
+
+// attributes of flush span and metrics
+const (
+	IDKey             = attribute.Key("buffer.id")
+	TriggerKey        = attribute.Key("buffer.flush.trigger")
+	BatchSizeKey      = attribute.Key("buffer.flush.batch_size")
+	RetriesKey        = attribute.Key("buffer.flush.retries")
+	DropReasonKey     = attribute.Key("buffer.drop.reason")
+	FlushErrReasonKey = attribute.Key("buffer.flush.error.reason")
+)
+
+var _ Metrics = &OTelMetrics{}
+
+// OTelMetrics Metrics implementation recording with OpenTelemetry metric instruments, every measurement has attribute buffer.id
+//
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+type OTelMetrics struct {
+	puts          instrument.Int64Counter
+	drops         instrument.Int64Counter
+	flushes       instrument.Int64Counter
+	flushErrors   instrument.Int64Counter
+	retries       instrument.Int64Counter
+	flushDuration instrument.Float64Histogram
+	batchSize     instrument.Int64Histogram
+
+	mutex          sync.Mutex
+	queueDepths    map[string]int64 // buffer id -> last queue depth, observed by gauge
+	containerSizes map[string]int64 // buffer id -> last container size, observed by gauge
+}
+
+// NewOTelMetrics new an OTelMetrics with instruments created by the meter of provider
+//
+//	@param provider metric.MeterProvider e.g. global.MeterProvider()
+//	@return metrics *OTelMetrics
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func NewOTelMetrics(provider metric.MeterProvider) (metrics *OTelMetrics, err error) {
+	meter := provider.Meter(InstrumentationName)
+	metrics = &OTelMetrics{
+		queueDepths:    make(map[string]int64),
+		containerSizes: make(map[string]int64),
+	}
+	if metrics.puts, err = meter.Int64Counter("buffer.puts", instrument.WithDescription("Data put into buffer.")); err != nil {
+		return nil, err
+	}
+	if metrics.drops, err = meter.Int64Counter("buffer.dropped", instrument.WithDescription("Data dropped by buffer, by reason.")); err != nil {
+		return nil, err
+	}
+	if metrics.flushes, err = meter.Int64Counter("buffer.flushes", instrument.WithDescription("Flushes of buffer, by trigger.")); err != nil {
+		return nil, err
+	}
+	if metrics.flushErrors, err = meter.Int64Counter("buffer.flush.errors", instrument.WithDescription("Failed flushes of buffer, by trigger and reason.")); err != nil {
+		return nil, err
+	}
+	if metrics.retries, err = meter.Int64Counter("buffer.flush.retries", instrument.WithDescription("Retries of failed flushes, by trigger.")); err != nil {
+		return nil, err
+	}
+	if metrics.flushDuration, err = meter.Float64Histogram("buffer.flush.duration", instrument.WithDescription("Latency of flushes including retries."), instrument.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if metrics.batchSize, err = meter.Int64Histogram("buffer.flush.batch_size", instrument.WithDescription("Data flushed in one flush."), instrument.WithUnit(unit.Dimensionless)); err != nil {
+		return nil, err
+	}
+	if _, err = meter.Int64ObservableGauge("buffer.queue_depth",
+		instrument.WithDescription("Data waiting in the channel of buffer."),
+		instrument.WithInt64Callback(metrics.observeGauge(metrics.queueDepths))); err != nil {
+		return nil, err
+	}
+	if _, err = meter.Int64ObservableGauge("buffer.container_size",
+		instrument.WithDescription("Data held by the container of buffer."),
+		instrument.WithInt64Callback(metrics.observeGauge(metrics.containerSizes))); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// ObservePut implement interface Metrics
+//
+//	@receiver metrics *OTelMetrics
+//	@param id string
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (metrics *OTelMetrics) ObservePut(id string) {
+	metrics.puts.Add(context.Background(), 1, IDKey.String(id))
+}
+
+// ObserveDrop implement interface Metrics
+//
+//	@receiver metrics *OTelMetrics
+//	@param id string
+//	@param reason string
+//	@param count int
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (metrics *OTelMetrics) ObserveDrop(id string, reason string, count int) {
+	metrics.drops.Add(context.Background(), int64(count), IDKey.String(id), DropReasonKey.String(reason))
+}
+
+// SetQueueDepth implement interface Metrics
+//
+//	@receiver metrics *OTelMetrics
+//	@param id string
+//	@param depth int
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (metrics *OTelMetrics) SetQueueDepth(id string, depth int) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.queueDepths[id] = int64(depth)
+}
+
+// SetContainerSize implement interface Metrics
+//
+//	@receiver metrics *OTelMetrics
+//	@param id string
+//	@param size int
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (metrics *OTelMetrics) SetContainerSize(id string, size int) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.containerSizes[id] = int64(size)
+}
+
+// ObserveFlush implement interface Metrics
+//
+//	@receiver metrics *OTelMetrics
+//	@param id string
+//	@param trigger FlushTrigger
+//	@param batchSize int
+//	@param latency time.Duration
+//	@param err error
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (metrics *OTelMetrics) ObserveFlush(id string, trigger FlushTrigger, batchSize int, latency time.Duration, err error) {
+	ctx := context.Background()
+	metrics.flushes.Add(ctx, 1, IDKey.String(id), TriggerKey.String(string(trigger)))
+	metrics.flushDuration.Record(ctx, latency.Seconds(), IDKey.String(id), TriggerKey.String(string(trigger)))
+	if batchSize >= 0 {
+		metrics.batchSize.Record(ctx, int64(batchSize), IDKey.String(id))
+	}
+	if err != nil {
+		metrics.flushErrors.Add(ctx, 1, IDKey.String(id), TriggerKey.String(string(trigger)), FlushErrReasonKey.String(FlushErrorReason(err)))
+	}
+}
+
+// ObserveRetry implement interface Metrics
+//
+//	@receiver metrics *OTelMetrics
+//	@param id string
+//	@param trigger FlushTrigger
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (metrics *OTelMetrics) ObserveRetry(id string, trigger FlushTrigger) {
+	metrics.retries.Add(context.Background(), 1, IDKey.String(id), TriggerKey.String(string(trigger)))
+}
+
+// Forget implement interface Metrics, stop observing the gauges of the buffer.
+// OpenTelemetry has no way to delete a series of counters and histograms, so they are kept by the SDK
+//
+//	@receiver metrics *OTelMetrics
+//	@param id string
+//	@author kevineluo
+//	@update 2026-10-19 16:10:21
+func (metrics *OTelMetrics) Forget(id string) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	delete(metrics.queueDepths, id)
+	delete(metrics.containerSizes, id)
+}
+
+// observeGauge build callback observing the last values of every buffer
+func (metrics *OTelMetrics) observeGauge(values map[string]int64) instrument.Int64Callback {
+	return func(ctx context.Context, observer instrument.Int64Observer) error {
+		metrics.mutex.Lock()
+		defer metrics.mutex.Unlock()
+		for id, value := range values {
+			observer.Observe(value, IDKey.String(id))
+		}
+		return nil
+	}
+}
+
+// addLink keep the span of a put to link it from the span of the flush carrying the data
+//
+//	@receiver buffer *Buffer[T]
+//	@param spanContext trace.SpanContext
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (buffer *Buffer[T]) addLink(spanContext trace.SpanContext) {
+	if !spanContext.IsValid() {
+		return
+	}
+	buffer.linksMutex.Lock()
+	defer buffer.linksMutex.Unlock()
+	if len(buffer.links) < maxFlushLinks {
+		buffer.links = append(buffer.links, trace.Link{SpanContext: spanContext})
+	}
+}
+
+// takeLinks take the links of data put since last flush
+//
+//	@receiver buffer *Buffer[T]
+//	@return links []trace.Link
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (buffer *Buffer[T]) takeLinks() (links []trace.Link) {
+	buffer.linksMutex.Lock()
+	defer buffer.linksMutex.Unlock()
+	links, buffer.links = buffer.links, nil
+	return
+}
+
+// startFlushSpan start span buffer.flush linked to the spans of puts
+//
+//	@receiver buffer *Buffer[T]
+//	@param trigger FlushTrigger
+//	@param batchSize int
+//	@param links []trace.Link
+//	@return trace.Span
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func (buffer *Buffer[T]) startFlushSpan(trigger FlushTrigger, batchSize int, links []trace.Link) trace.Span {
+	attrs := []attribute.KeyValue{IDKey.String(buffer.ID), TriggerKey.String(string(trigger))}
+	if batchSize >= 0 {
+		attrs = append(attrs, BatchSizeKey.Int(batchSize))
+	}
+	_, span := buffer.tracer.Start(context.Background(), "buffer.flush",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+		trace.WithLinks(links...),
+	)
+	return span
+}
+
+// endFlushSpan end span buffer.flush with retries and error
+//
+//	@param span trace.Span
+//	@param retries int
+//	@param err error
+//	@author kevineluo
+//	@update 2026-10-18 19:46:05
+func endFlushSpan(span trace.Span, retries int, err error) {
+	span.SetAttributes(RetriesKey.Int(retries))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
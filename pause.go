@@ -0,0 +1,80 @@
+package buffer
+
+// Pause stop automatic and size-triggered flushes, e.g. when the sink is under maintenance, until Resume is called.
+// the buffer keeps accepting data while paused, once the container is full it stops receiving data,
+// so Put blocks after the channel of buffer is full. manual Flush, Close and Shutdown still flush the container
+//
+//	@receiver buffer *Buffer[T]
+//	@return error ErrClosed if the buffer is closed
+//	@author kevineluo
+//	@update 2026-10-18 21:42:16
+func (buffer *Buffer[T]) Pause() error {
+	return buffer.setPaused(true)
+}
+
+// Resume restart automatic and size-triggered flushes stopped by Pause, a full container is flushed immediately
+//
+//	@receiver buffer *Buffer[T]
+//	@return error ErrClosed if the buffer is closed
+//	@author kevineluo
+//	@update 2026-10-18 21:42:16
+func (buffer *Buffer[T]) Resume() error {
+	return buffer.setPaused(false)
+}
+
+// Paused check if flushing of the buffer is paused
+//
+//	@receiver buffer *Buffer[T]
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-18 21:42:16
+func (buffer *Buffer[T]) Paused() bool {
+	return buffer.paused.Load()
+}
+
+// setPaused set the paused flag, and signal run to stop or restart the ticker
+//
+//	@receiver buffer *Buffer[T]
+//	@param paused bool
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 21:42:16
+func (buffer *Buffer[T]) setPaused(paused bool) error {
+	if buffer.closed() {
+		return ErrClosed
+	}
+	if buffer.paused.Swap(paused) == paused {
+		return nil
+	}
+	select {
+	case buffer.pauseSignalChan <- void{}:
+		return nil
+	case <-buffer.context.Done():
+		return ErrClosed
+	}
+}
+
+// handlePause apply the paused flag set by Pause or Resume, must be called by run
+//
+//	@receiver buffer *Buffer[T]
+//	@author kevineluo
+//	@update 2026-10-18 21:42:16
+func (buffer *Buffer[T]) handlePause() {
+	if buffer.paused.Load() {
+		buffer.Logger.Info("[Buffer.handlePause] flushing is paused", "ID", buffer.ID)
+		buffer.autoFlushTicker.Stop()
+		return
+	}
+
+	buffer.Logger.Info("[Buffer.handlePause] flushing is resumed", "ID", buffer.ID)
+	if buffer.container.IsFull() {
+		if buffer.SyncAutoFlush {
+			buffer.flush(FlushTriggerFull)
+		} else {
+			buffer.goFlush(FlushTriggerFull)
+		}
+	}
+	if !buffer.DisableAutoFlush {
+		buffer.autoFlushTicker.Reset(buffer.FlushInterval)
+	}
+}
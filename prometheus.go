@@ -0,0 +1,263 @@
+package buffer
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	_ Metrics              = &PrometheusMetrics{}
+	_ prometheus.Collector = &PrometheusMetrics{}
+	_ http.Handler         = &PrometheusMetrics{}
+)
+
+// PrometheusMetricsConfig config of PrometheusMetrics
+//
+//	@author kevineluo
+//	@update 2026-10-18 19:28:40
+type PrometheusMetricsConfig struct {
+	Namespace        string    // prefix of metric names, default is "go_buffer"
+	LatencyBuckets   []float64 // buckets of flush latency histogram in seconds, default is 5ms ~ 10s
+	BatchSizeBuckets []float64 // buckets of flush batch size histogram, default is 1 ~ 100000
+}
+
+// Validate check config and set default value
+//
+//	@receiver config *PrometheusMetricsConfig
+//	@return err error
+//	@author kevineluo
+//	@update 2026-10-18 19:28:40
+func (config *PrometheusMetricsConfig) Validate() (err error) {
+	if config.Namespace == "" {
+		config.Namespace = "go_buffer"
+	}
+	if len(config.LatencyBuckets) == 0 {
+		config.LatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	}
+	if len(config.BatchSizeBuckets) == 0 {
+		config.BatchSizeBuckets = []float64{1, 10, 100, 1000, 10000, 100000}
+	}
+	for _, buckets := range [][]float64{config.LatencyBuckets, config.BatchSizeBuckets} {
+		if !sort.Float64sAreSorted(buckets) {
+			return fmt.Errorf("[PrometheusMetricsConfig.Validate] buckets should be in increasing order: %v", buckets)
+		}
+	}
+	return
+}
+
+// PrometheusMetrics Metrics implementation backed by metric vectors of client_golang, every series is labelled by buffer="{Config.ID}".
+// it's a prometheus.Collector, register it to the registry of your application, e.g.
+//
+//	prometheus.MustRegister(metrics)
+//
+// or serve it alone as the scrape endpoint
+//
+//	http.Handle("/metrics", metrics)
+//
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+type PrometheusMetrics struct {
+	puts          *prometheus.CounterVec
+	dropped       *prometheus.CounterVec
+	queueDepth    *prometheus.GaugeVec
+	containerSize *prometheus.GaugeVec
+	flushes       *prometheus.CounterVec
+	flushErrors   *prometheus.CounterVec
+	flushRetries  *prometheus.CounterVec
+	flushDuration *prometheus.HistogramVec
+	flushBatch    *prometheus.HistogramVec
+
+	handler http.Handler // serve a registry holding only this collector
+}
+
+// NewPrometheusMetrics new a PrometheusMetrics
+//
+//	@param config PrometheusMetricsConfig
+//	@return *PrometheusMetrics
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func NewPrometheusMetrics(config PrometheusMetricsConfig) (*PrometheusMetrics, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	namespace := config.Namespace
+	metrics := &PrometheusMetrics{
+		puts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "puts_total", Help: "Data put into buffer.",
+		}, []string{"buffer"}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "dropped_total", Help: "Data dropped by buffer, by reason.",
+		}, []string{"buffer", "reason"}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "queue_depth", Help: "Data waiting in the channel of buffer.",
+		}, []string{"buffer"}),
+		containerSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "container_size", Help: "Data held by the container of buffer.",
+		}, []string{"buffer"}),
+		flushes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "flushes_total", Help: "Flushes of buffer, by trigger.",
+		}, []string{"buffer", "trigger"}),
+		flushErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "flush_errors_total", Help: "Failed flushes of buffer, by trigger and reason.",
+		}, []string{"buffer", "trigger", "reason"}),
+		flushRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "flush_retries_total", Help: "Retries of failed flushes, by trigger.",
+		}, []string{"buffer", "trigger"}),
+		flushDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "flush_duration_seconds", Help: "Latency of flushes including retries.", Buckets: config.LatencyBuckets,
+		}, []string{"buffer", "trigger"}),
+		flushBatch: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "flush_batch_size", Help: "Data flushed in one flush.", Buckets: config.BatchSizeBuckets,
+		}, []string{"buffer"}),
+	}
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(metrics); err != nil {
+		return nil, fmt.Errorf("[NewPrometheusMetrics] %w", err)
+	}
+	metrics.handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	return metrics, nil
+}
+
+// ObservePut implement interface Metrics
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param id string
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func (metrics *PrometheusMetrics) ObservePut(id string) {
+	metrics.puts.WithLabelValues(id).Inc()
+}
+
+// ObserveDrop implement interface Metrics
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param id string
+//	@param reason string
+//	@param count int
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func (metrics *PrometheusMetrics) ObserveDrop(id string, reason string, count int) {
+	metrics.dropped.WithLabelValues(id, reason).Add(float64(count))
+}
+
+// SetQueueDepth implement interface Metrics
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param id string
+//	@param depth int
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func (metrics *PrometheusMetrics) SetQueueDepth(id string, depth int) {
+	metrics.queueDepth.WithLabelValues(id).Set(float64(depth))
+}
+
+// SetContainerSize implement interface Metrics
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param id string
+//	@param size int
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func (metrics *PrometheusMetrics) SetContainerSize(id string, size int) {
+	metrics.containerSize.WithLabelValues(id).Set(float64(size))
+}
+
+// ObserveFlush implement interface Metrics
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param id string
+//	@param trigger FlushTrigger
+//	@param batchSize int
+//	@param latency time.Duration
+//	@param err error
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func (metrics *PrometheusMetrics) ObserveFlush(id string, trigger FlushTrigger, batchSize int, latency time.Duration, err error) {
+	metrics.flushes.WithLabelValues(id, string(trigger)).Inc()
+	metrics.flushDuration.WithLabelValues(id, string(trigger)).Observe(latency.Seconds())
+	if batchSize >= 0 {
+		metrics.flushBatch.WithLabelValues(id).Observe(float64(batchSize))
+	}
+	if err != nil {
+		metrics.flushErrors.WithLabelValues(id, string(trigger), FlushErrorReason(err)).Inc()
+	}
+}
+
+// ObserveRetry implement interface Metrics
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param id string
+//	@param trigger FlushTrigger
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func (metrics *PrometheusMetrics) ObserveRetry(id string, trigger FlushTrigger) {
+	metrics.flushRetries.WithLabelValues(id, string(trigger)).Inc()
+}
+
+// Forget implement interface Metrics, delete every series labelled by the buffer
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param id string
+//	@author kevineluo
+//	@update 2026-10-19 16:10:21
+func (metrics *PrometheusMetrics) Forget(id string) {
+	labels := prometheus.Labels{"buffer": id}
+	metrics.puts.DeletePartialMatch(labels)
+	metrics.dropped.DeletePartialMatch(labels)
+	metrics.queueDepth.DeletePartialMatch(labels)
+	metrics.containerSize.DeletePartialMatch(labels)
+	metrics.flushes.DeletePartialMatch(labels)
+	metrics.flushErrors.DeletePartialMatch(labels)
+	metrics.flushRetries.DeletePartialMatch(labels)
+	metrics.flushDuration.DeletePartialMatch(labels)
+	metrics.flushBatch.DeletePartialMatch(labels)
+}
+
+// Describe implement interface prometheus.Collector
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param descs chan<- *prometheus.Desc
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func (metrics *PrometheusMetrics) Describe(descs chan<- *prometheus.Desc) {
+	for _, collector := range metrics.collectors() {
+		collector.Describe(descs)
+	}
+}
+
+// Collect implement interface prometheus.Collector
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param metricChan chan<- prometheus.Metric
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func (metrics *PrometheusMetrics) Collect(metricChan chan<- prometheus.Metric) {
+	for _, collector := range metrics.collectors() {
+		collector.Collect(metricChan)
+	}
+}
+
+// ServeHTTP implement interface http.Handler, serve the metrics of this collector only in the format negotiated by promhttp
+//
+//	@receiver metrics *PrometheusMetrics
+//	@param writer http.ResponseWriter
+//	@param request *http.Request
+//	@author kevineluo
+//	@update 2026-10-19 12:31:05
+func (metrics *PrometheusMetrics) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	metrics.handler.ServeHTTP(writer, request)
+}
+
+// collectors return all metric vectors
+func (metrics *PrometheusMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		metrics.puts, metrics.dropped, metrics.queueDepth, metrics.containerSize,
+		metrics.flushes, metrics.flushErrors, metrics.flushRetries, metrics.flushDuration, metrics.flushBatch,
+	}
+}
@@ -0,0 +1,267 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultRegistry registry which buffers register into when Config.Registry is nil
+var DefaultRegistry = NewRegistry()
+
+// Registry hold buffers of a process by Config.ID, for flushing and closing them together and serving AdminHandler.
+// a buffer registers itself on creation and unregisters after it's closed. IDs are unique in a registry set by Config.Registry,
+// while a buffer registered into DefaultRegistry implicitly replaces the one with the same ID
+//
+//	@author kevineluo
+//	@update 2026-10-19 14:25:03
+type Registry struct {
+	mutex   sync.RWMutex
+	buffers map[string]RegisteredBuffer
+}
+
+// RegisteredBuffer buffer held by Registry, implemented by *Buffer[T]
+//
+//	@author kevineluo
+//	@update 2026-10-18 20:38:26
+type RegisteredBuffer interface {
+	ManagedBuffer
+	Shutdown(ctx context.Context) error
+	Done() <-chan struct{} // closed after the buffer is closed and drained
+}
+
+var _ RegisteredBuffer = &Buffer[int]{}
+
+// NewRegistry new an empty Registry
+//
+//	@return *Registry
+//	@author kevineluo
+//	@update 2026-10-18 20:38:26
+func NewRegistry() *Registry {
+	return &Registry{buffers: make(map[string]RegisteredBuffer)}
+}
+
+// Register add buffer into registry by its ID
+//
+//	@receiver registry *Registry
+//	@param buffer RegisteredBuffer
+//	@return error ErrDuplicateID if another buffer with the same ID is registered
+//	@author kevineluo
+//	@update 2026-10-19 12:55:48
+func (registry *Registry) Register(buffer RegisteredBuffer) error {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	id := buffer.Stats().ID
+	if registered, ok := registry.buffers[id]; ok && registered != buffer {
+		return fmt.Errorf("[Registry.Register] %w: %s", ErrDuplicateID, id)
+	}
+	registry.buffers[id] = buffer
+	return nil
+}
+
+// replace add buffer into registry by its ID, replacing the buffer with the same ID
+//
+//	@receiver registry *Registry
+//	@param buffer RegisteredBuffer
+//	@author kevineluo
+//	@update 2026-10-19 14:25:03
+func (registry *Registry) replace(buffer RegisteredBuffer) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.buffers[buffer.Stats().ID] = buffer
+}
+
+// Unregister remove buffer from registry, nothing happens if the buffer registered by its ID is another one(e.g. it has been replaced)
+//
+//	@receiver registry *Registry
+//	@param buffer RegisteredBuffer
+//	@author kevineluo
+//	@update 2026-10-19 14:25:03
+func (registry *Registry) Unregister(buffer RegisteredBuffer) {
+	registry.remove(buffer)
+}
+
+// remove remove buffer from registry like Unregister, return false if the buffer registered by its ID is another one
+//
+//	@receiver registry *Registry
+//	@param buffer RegisteredBuffer
+//	@return bool
+//	@author kevineluo
+//	@update 2026-10-19 16:10:21
+func (registry *Registry) remove(buffer RegisteredBuffer) bool {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	id := buffer.Stats().ID
+	if registry.buffers[id] != buffer {
+		return false
+	}
+	delete(registry.buffers, id)
+	return true
+}
+
+// Get return the buffer of id, nil if it's not registered
+//
+//	@receiver registry *Registry
+//	@param id string
+//	@return RegisteredBuffer
+//	@author kevineluo
+//	@update 2026-10-18 20:38:26
+func (registry *Registry) Get(id string) RegisteredBuffer {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	return registry.buffers[id]
+}
+
+// Buffers return registered buffers sorted by ID
+//
+//	@receiver registry *Registry
+//	@return []RegisteredBuffer
+//	@author kevineluo
+//	@update 2026-10-18 20:38:26
+func (registry *Registry) Buffers() []RegisteredBuffer {
+	registry.mutex.RLock()
+	ids := make([]string, 0, len(registry.buffers))
+	for id := range registry.buffers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	buffers := make([]RegisteredBuffer, 0, len(ids))
+	for _, id := range ids {
+		buffers = append(buffers, registry.buffers[id])
+	}
+	registry.mutex.RUnlock()
+	return buffers
+}
+
+// Handler return an AdminHandler mounted under "/debug/buffers" serving registered buffers
+//
+//	@receiver registry *Registry
+//	@return *AdminHandler
+//	@author kevineluo
+//	@update 2026-10-18 20:38:26
+func (registry *Registry) Handler() *AdminHandler {
+	return NewAdminHandler(func() []ManagedBuffer {
+		buffers := registry.Buffers()
+		managed := make([]ManagedBuffer, 0, len(buffers))
+		for _, buffer := range buffers {
+			managed = append(managed, buffer)
+		}
+		return managed
+	})
+}
+
+// FlushAll flush all registered buffers synchronously and concurrently, return when they are done or ctx is done
+//
+//	@receiver registry *Registry
+//	@param ctx context.Context
+//	@return error joined errors of buffers
+//	@author kevineluo
+//	@update 2026-10-18 20:38:26
+func (registry *Registry) FlushAll(ctx context.Context) error {
+	return registry.each(ctx, func(buffer RegisteredBuffer) error {
+		return buffer.Flush(false)
+	})
+}
+
+// CloseAll shut down all registered buffers with Buffer.Shutdown, and wait for them to drain their data and finish the last flush or ctx is done
+//
+//	@receiver registry *Registry
+//	@param ctx context.Context
+//	@return error joined errors of buffers
+//	@author kevineluo
+//	@update 2026-10-18 20:57:10
+func (registry *Registry) CloseAll(ctx context.Context) error {
+	return registry.each(ctx, func(buffer RegisteredBuffer) error {
+		return buffer.Shutdown(ctx)
+	})
+}
+
+// CloseOnSignal block until one of signals is received(SIGINT and SIGTERM by default) or ctx is done,
+// then close all registered buffers with CloseAll, waiting at most timeout for them to drain. usually run in main:
+//
+//	go func() {
+//		if err := buffer.DefaultRegistry.CloseOnSignal(ctx, 10*time.Second); err != nil {
+//			log.Println(err)
+//		}
+//		os.Exit(0)
+//	}()
+//
+//	@receiver registry *Registry
+//	@param ctx context.Context
+//	@param timeout time.Duration
+//	@param signals ...os.Signal
+//	@return error
+//	@author kevineluo
+//	@update 2026-10-18 20:38:26
+func (registry *Registry) CloseOnSignal(ctx context.Context, timeout time.Duration, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, signals...)
+	defer signal.Stop(signalChan)
+
+	select {
+	case <-signalChan:
+	case <-ctx.Done():
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return registry.CloseAll(closeCtx)
+}
+
+// each call action on every registered buffer concurrently, and wait for them or ctx
+//
+//	@receiver registry *Registry
+//	@param ctx context.Context
+//	@param action func(buffer RegisteredBuffer) error
+//	@return error joined errors of actions, or ctx error with IDs of unfinished buffers
+//	@author kevineluo
+//	@update 2026-10-18 20:38:26
+func (registry *Registry) each(ctx context.Context, action func(buffer RegisteredBuffer) error) error {
+	buffers := registry.Buffers()
+	var (
+		mutex    sync.Mutex
+		errs     []error
+		finished = make(map[string]bool, len(buffers))
+		done     = make(chan struct{}, len(buffers))
+	)
+	for _, buffer := range buffers {
+		go func(buffer RegisteredBuffer) {
+			err := action(buffer)
+			mutex.Lock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("buffer %s: %w", buffer.Stats().ID, err))
+			}
+			finished[buffer.Stats().ID] = true
+			mutex.Unlock()
+			done <- struct{}{}
+		}(buffer)
+	}
+
+	for range buffers {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			mutex.Lock()
+			defer mutex.Unlock()
+			var unfinished []string
+			for _, buffer := range buffers {
+				if id := buffer.Stats().ID; !finished[id] {
+					unfinished = append(unfinished, id)
+				}
+			}
+			return errors.Join(append(errs, fmt.Errorf("[Registry] %w, unfinished buffers: %v", ctx.Err(), unfinished))...)
+		}
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	return errors.Join(errs...)
+}
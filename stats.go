@@ -0,0 +1,139 @@
+package buffer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// State lifecycle state of Buffer
+type State int32
+
+const (
+	StateRunning State = iota // handling data
+	StateClosing              // Close is called, draining data and doing the last flush
+	StateClosed               // the last flush is done and resources are released
+	StatePaused               // handling data with automatic and size-triggered flushes paused, see Buffer.Pause
+)
+
+// String implement interface fmt.Stringer
+//
+//	@receiver state State
+//	@return string
+//	@author kevineluo
+//	@update 2026-10-18 21:42:16
+func (state State) String() string {
+	switch state {
+	case StateRunning:
+		return "running"
+	case StateClosing:
+		return "closing"
+	case StateClosed:
+		return "closed"
+	case StatePaused:
+		return "paused"
+	default:
+		return "unknown"
+	}
+}
+
+// Stats snapshot of Buffer state, returned by Buffer.Stats
+//
+//	@author kevineluo
+//	@update 2026-10-18 20:03:17
+type Stats struct {
+	ID                string
+	State             State
+	QueueDepth        int           // data waiting in the channel of buffer
+	ContainerLen      int           // data held by container, -1 if the container doesn't implement `Len() int`
+	ContainerBytes    int           // bytes held by container, -1 if unknown, e.g. the container doesn't implement `Bytes() int`
+	LastFlushTime     time.Time     // when the last flush finished, zero if there is no flush yet
+	LastFlushDuration time.Duration // latency of the last flush including retries
+	LastFlushError    error         // error of the last flush, nil if it succeeded
+	FlushedRecords    int64         // total data flushed successfully, only counted for containers implement `Len() int`
+	InFlightFlushes   int           // flushes running now
+	DroppedErrors     int64         // errors dropped because the error channel is full
+}
+
+// stats state of Buffer updated by its goroutines, so Stats can be read without touching the container, which is not thread safe.
+// length and bytes of container are only read by run(or cleanup after run returns), async flushes only record their results
+type stats struct {
+	state           atomic.Int32
+	containerLen    atomic.Int64
+	containerBytes  atomic.Int64
+	flushedRecords  atomic.Int64
+	inFlightFlushes atomic.Int32
+	droppedErrors   atomic.Int64
+
+	mutex             sync.Mutex
+	lastFlushTime     time.Time
+	lastFlushDuration time.Duration
+	lastFlushError    error
+}
+
+// Stats return a snapshot of the buffer state, it's safe to call concurrently and after the buffer is closed
+//
+//	@receiver buffer *Buffer[T]
+//	@return Stats
+//	@author kevineluo
+//	@update 2026-10-18 21:42:16
+func (buffer *Buffer[T]) Stats() Stats {
+	state := State(buffer.stats.state.Load())
+	if state == StateRunning && buffer.closed() {
+		state = StateClosing
+	} else if state == StateRunning && buffer.paused.Load() {
+		state = StatePaused
+	}
+	buffer.stats.mutex.Lock()
+	defer buffer.stats.mutex.Unlock()
+	return Stats{
+		ID:                buffer.ID,
+		State:             state,
+		QueueDepth:        len(buffer.dataChan),
+		ContainerLen:      int(buffer.stats.containerLen.Load()),
+		ContainerBytes:    int(buffer.stats.containerBytes.Load()),
+		LastFlushTime:     buffer.stats.lastFlushTime,
+		LastFlushDuration: buffer.stats.lastFlushDuration,
+		LastFlushError:    buffer.stats.lastFlushError,
+		FlushedRecords:    buffer.stats.flushedRecords.Load(),
+		InFlightFlushes:   int(buffer.stats.inFlightFlushes.Load()),
+		DroppedErrors:     buffer.stats.droppedErrors.Load(),
+	}
+}
+
+// updateContainerStats record length and bytes of container into stats and metrics, must be called by run,
+// or cleanup after run returns, async flushes signal run to call it when they are done
+//
+//	@receiver buffer *Buffer[T]
+//	@author kevineluo
+//	@update 2026-10-19 13:06:32
+func (buffer *Buffer[T]) updateContainerStats() {
+	size := buffer.containerLen()
+	buffer.stats.containerLen.Store(int64(size))
+	if size >= 0 {
+		buffer.Metrics.SetContainerSize(buffer.ID, size)
+	}
+	bytes := -1
+	if byter, ok := buffer.container.(interface{ Bytes() int }); ok {
+		bytes = byter.Bytes()
+	}
+	buffer.stats.containerBytes.Store(int64(bytes))
+}
+
+// recordFlush record the result of a flush into stats
+//
+//	@receiver buffer *Buffer[T]
+//	@param batchSize int
+//	@param finish time.Time
+//	@param latency time.Duration
+//	@param err error
+//	@author kevineluo
+//	@update 2026-10-18 20:03:17
+func (buffer *Buffer[T]) recordFlush(batchSize int, finish time.Time, latency time.Duration, err error) {
+	if err == nil && batchSize > 0 {
+		buffer.stats.flushedRecords.Add(int64(batchSize))
+	}
+	buffer.stats.mutex.Lock()
+	defer buffer.stats.mutex.Unlock()
+	buffer.stats.lastFlushTime, buffer.stats.lastFlushDuration, buffer.stats.lastFlushError = finish, latency, err
+}
@@ -0,0 +1,84 @@
+package buffer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAdminHandler(t *testing.T) {
+	Convey("Given an AdminHandler managing a Buffer", t, func() {
+		flushed := 0
+		arrayContainer := container.NewArrayContainer(10, false, func(array []int) error {
+			flushed += len(array)
+			return nil
+		})
+		flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+			ID:               "admin-buffer",
+			DisableAutoFlush: true,
+		})
+		So(err, ShouldBeNil)
+		defer flushBuffer.Close()
+
+		handler := buffer.NewAdminHandler(func() []buffer.ManagedBuffer { return []buffer.ManagedBuffer{flushBuffer} })
+		serve := func(method, path string) (*httptest.ResponseRecorder, map[string]any) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+			var body map[string]any
+			_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+			return recorder, body
+		}
+
+		Convey("Listing should return stats of every buffer", func() {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/buffers/", nil))
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+
+			var list []map[string]any
+			So(json.Unmarshal(recorder.Body.Bytes(), &list), ShouldBeNil)
+			So(list, ShouldHaveLength, 1)
+			So(list[0]["id"], ShouldEqual, "admin-buffer")
+			So(list[0]["state"], ShouldEqual, "running")
+		})
+
+		Convey("Posting flush should flush the buffer synchronously", func() {
+			So(flushBuffer.Put(1), ShouldBeNil)
+			So(flushBuffer.Put(2), ShouldBeNil)
+			recorder, body := serve(http.MethodPost, "/debug/buffers/admin-buffer/flush")
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+			So(body["flushed_records"], ShouldEqual, 2)
+			So(flushed, ShouldEqual, 2)
+		})
+
+		Convey("Posting pause and resume should pause and resume flushing", func() {
+			recorder, body := serve(http.MethodPost, "/debug/buffers/admin-buffer/pause")
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+			So(body["state"], ShouldEqual, "paused")
+			recorder, body = serve(http.MethodPost, "/debug/buffers/admin-buffer/resume")
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+			So(body["state"], ShouldEqual, "running")
+		})
+
+		Convey("Posting close should close the buffer, and closing again should conflict", func() {
+			recorder, _ := serve(http.MethodPost, "/debug/buffers/admin-buffer/close")
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+			recorder, _ = serve(http.MethodPost, "/debug/buffers/admin-buffer/close")
+			So(recorder.Code, ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("Bad requests should be rejected", func() {
+			recorder, _ := serve(http.MethodGet, "/debug/buffers/unknown")
+			So(recorder.Code, ShouldEqual, http.StatusNotFound)
+			recorder, _ = serve(http.MethodGet, "/debug/buffers/admin-buffer/flush")
+			So(recorder.Code, ShouldEqual, http.StatusMethodNotAllowed)
+			recorder, _ = serve(http.MethodPost, "/debug/buffers/admin-buffer/explode")
+			So(recorder.Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
@@ -0,0 +1,151 @@
+package chgen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kevinello/go-buffer/internal/chgen"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const eventSource = `package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Event struct {
+	ID        uint64     ` + "`ch:\"id\"`" + `
+	Service   string     ` + "`ch:\"service,lowcardinality\"`" + `
+	Count     int        ` + "`ch:\"count\"`" + `
+	Timestamp time.Time  ` + "`ch:\"timestamp,datetime64=3\"`" + `
+	Seen      *time.Time ` + "`ch:\"seen\"`" + `
+	TraceID   uuid.UUID  ` + "`ch:\"trace_id\"`" + `
+	Tags      []string   ` + "`ch:\"tags,lowcardinality\"`" + `
+	Internal  string     ` + "`ch:\"-\"`" + `
+	internal  string
+}
+`
+
+const describeOutput = "name\ttype\tdefault_type\tdefault_expression\tcomment\tcodec_expression\tttl_expression\n" +
+	"id\tUInt64\t\t\t\t\t\n" +
+	"ts\tDateTime64(6, 'UTC')\t\t\t\t\t\n" +
+	"date\tDate\tMATERIALIZED\ttoDate(ts)\t\t\t\n" +
+	"request_url\tNullable(String)\t\t\t\t\t\n" +
+	"labels\tArray(LowCardinality(String))\t\t\t\t\t\n" +
+	"payload\tString\tDEFAULT\t''\t\t\t\n"
+
+// typeCheck parse and type-check sources as one package, imports are resolved from source by the module of this test
+func typeCheck(sources map[string][]byte) error {
+	dir, err := filepath.Abs(".")
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(sources))
+	for name, source := range sources {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), source, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = config.Check(files[0].Name.Name, fset, files, nil)
+	return err
+}
+
+func TestGenerate(t *testing.T) {
+	Convey("Given a tagged Go struct", t, func() {
+		table, err := chgen.ParseStruct("event.go", []byte(eventSource), "Event")
+		So(err, ShouldBeNil)
+
+		Convey("Columns should follow the tag rule of ClickHouseMapper", func() {
+			So(table.Package, ShouldEqual, "events")
+			types := make([]string, 0, len(table.Columns))
+			for _, column := range table.Columns {
+				types = append(types, column.Name+" "+column.Type())
+			}
+			So(types, ShouldResemble, []string{
+				"id UInt64",
+				"service LowCardinality(String)",
+				"count Int64",
+				"timestamp DateTime64(3)",
+				"seen Nullable(DateTime)",
+				"trace_id UUID",
+				"tags Array(LowCardinality(String))",
+			})
+		})
+
+		Convey("When generating code", func() {
+			code, err := chgen.Generate(table)
+			So(err, ShouldBeNil)
+
+			Convey("The code should hold the input factory and Insert method", func() {
+				So(typeCheck(map[string][]byte{"event.go": []byte(eventSource), "event_clickhouse_gen.go": code}), ShouldBeNil)
+				source := string(code)
+				So(source, ShouldStartWith, "// Code generated by go-buffer-gen. DO NOT EDIT.")
+				So(source, ShouldContainSubstring, "func NewEventInput() proto.Input {")
+				So(source, ShouldContainSubstring, `{Name: "timestamp", Data: new(proto.ColDateTime64).WithPrecision(3)},`)
+				So(source, ShouldContainSubstring, "func (row Event) Insert(input proto.Input) error {")
+				So(source, ShouldContainSubstring, "input[2].Data.(*proto.ColInt64).Append(int64(row.Count))")
+				So(source, ShouldContainSubstring, "input[4].Data.(*proto.ColNullable[time.Time]).Append(proto.Null[time.Time]())")
+				So(source, ShouldNotContainSubstring, "uuid\"")
+				So(source, ShouldNotContainSubstring, "Internal")
+			})
+		})
+	})
+
+	Convey("Given the output of DESCRIBE TABLE", t, func() {
+		table, err := chgen.ParseDescribe(strings.NewReader(describeOutput), "Request", []string{"payload"})
+		So(err, ShouldBeNil)
+		table.Package = "requests"
+
+		Convey("Columns which can't be inserted or are excluded should be skipped", func() {
+			So(table.Columns, ShouldHaveLength, 4)
+			So(table.Columns[1].Type(), ShouldEqual, "DateTime64(6)")
+			So(table.Columns[2].Field, ShouldEqual, "RequestURL")
+		})
+
+		Convey("When generating code", func() {
+			code, err := chgen.Generate(table)
+			So(err, ShouldBeNil)
+
+			Convey("The struct should be defined with ch tags", func() {
+				So(typeCheck(map[string][]byte{"request_clickhouse_gen.go": code}), ShouldBeNil)
+				So(string(code), ShouldContainSubstring, "type Request struct {")
+				So(string(code), ShouldContainSubstring, "RequestURL *string")
+				So(string(code), ShouldContainSubstring, "`ch:\"labels,lowcardinality\"`")
+				So(string(code), ShouldContainSubstring, "`ch:\"ts,datetime64=6\"`")
+			})
+		})
+
+		Convey("When a column type is unsupported", func() {
+			_, err := chgen.ParseDescribe(strings.NewReader("attrs\tMap(String, String)\n"), "Request", nil)
+
+			Convey("ParseDescribe should fail", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "Map(String, String)")
+			})
+		})
+
+		Convey("When a column is LowCardinality(Nullable(String))", func() {
+			_, err := chgen.ParseDescribe(strings.NewReader("service\tLowCardinality(Nullable(String))\n"), "Request", nil)
+
+			Convey("ParseDescribe should fail with the limitation, and the column can be excluded", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "LowCardinality(Nullable(X)) can't be inserted by ch-go")
+				_, err = chgen.ParseDescribe(strings.NewReader("id\tUInt64\nservice\tLowCardinality(Nullable(String))\n"), "Request", []string{"service"})
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
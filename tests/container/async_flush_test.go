@@ -0,0 +1,199 @@
+package container
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// lockedHandler serve requests of handler one at a time
+type lockedHandler struct {
+	mutex   sync.Mutex
+	handler http.Handler
+}
+
+func (locked *lockedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	locked.mutex.Lock()
+	defer locked.mutex.Unlock()
+	locked.handler.ServeHTTP(w, r)
+}
+
+// putAsync put events into a Buffer flushing the container asynchronously on full and on ticks from two goroutines,
+// then shut it down and return its stats
+func putAsync(flushContainer container.Container[objectEvent], events int) buffer.Stats {
+	flushBuffer, _, err := buffer.NewBuffer[objectEvent](context.Background(), flushContainer, buffer.Config{
+		ID:            "async-buffer",
+		ChanBufSize:   4,
+		FlushInterval: time.Millisecond,
+		Registry:      buffer.NewRegistry(),
+	})
+	So(err, ShouldBeNil)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 2; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := worker; i < events; i += 2 {
+				flushBuffer.Put(objectEvent{ID: i, Name: "event"})
+			}
+		}(worker)
+	}
+	wg.Wait()
+	So(flushBuffer.Shutdown(context.Background()), ShouldBeNil)
+	return flushBuffer.Stats()
+}
+
+func TestAsyncFlushInBuffer(t *testing.T) {
+	const events = 200
+
+	Convey("Given batch containers flushed asynchronously by a Buffer while data keeps being put", t, func() {
+		Convey("ChanContainer should emit every element", func() {
+			batches := make(chan []objectEvent)
+			chanContainer, err := container.NewChanContainer(container.ChanConfig[objectEvent]{FlushSize: 7, Chan: batches})
+			So(err, ShouldBeNil)
+			received := make(chan int)
+			go func() {
+				count := 0
+				for batch := range batches {
+					count += len(batch)
+				}
+				received <- count
+			}()
+			stats := putAsync(chanContainer, events)
+			close(batches)
+			So(<-received, ShouldEqual, events)
+			So(stats.ContainerLen, ShouldEqual, 0)
+		})
+
+		Convey("HTTPContainer should send every element", func() {
+			lines := 0
+			server := httptest.NewServer(&lockedHandler{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				lines += strings.Count(string(body), "\n")
+			})})
+			defer server.Close()
+			httpContainer, err := container.NewHTTPContainer(container.HTTPConfig[objectEvent]{
+				FlushSize: 7,
+				URL:       server.URL,
+				Encoder:   container.JSONLEncoder[objectEvent]{},
+			})
+			So(err, ShouldBeNil)
+			putAsync(httpContainer, events)
+			So(lines, ShouldEqual, events)
+		})
+
+		Convey("ElasticsearchBulkContainer should index every element", func() {
+			bulk := &bulkServer{busy: map[int]bool{}}
+			server := httptest.NewServer(&lockedHandler{handler: bulk})
+			defer server.Close()
+			esContainer, err := container.NewElasticsearchBulkContainer(container.ElasticsearchBulkConfig[objectEvent]{
+				ID:        "async-buffer",
+				FlushSize: 7,
+				URL:       server.URL,
+				Index:     func(event objectEvent) string { return "events" },
+			})
+			So(err, ShouldBeNil)
+			putAsync(esContainer, events)
+			So(bulk.indexed, ShouldHaveLength, events)
+		})
+
+		Convey("ObjectStoreContainer should upload every element", func() {
+			root := t.TempDir()
+			objectContainer, err := container.NewObjectStoreContainer(container.ObjectStoreConfig[objectEvent]{
+				ID:        "async-buffer",
+				FlushSize: 7,
+				Store:     &container.LocalObjectStore{Root: root},
+			})
+			So(err, ShouldBeNil)
+			putAsync(objectContainer, events)
+
+			lines := 0
+			filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					file, _ := os.Open(path)
+					defer file.Close()
+					for scanner := bufio.NewScanner(file); scanner.Scan(); {
+						lines++
+					}
+				}
+				return nil
+			})
+			So(lines, ShouldEqual, events)
+		})
+
+		Convey("ParquetContainer should write every element", func() {
+			var files []*memoryFile
+			parquetContainer, err := container.NewParquetContainer[parquetEvent](container.ParquetConfig{
+				FlushSize:        7,
+				RowGroupsPerFile: 3,
+				CreateFile: func(seq int) (io.WriteCloser, error) {
+					file := &memoryFile{}
+					files = append(files, file)
+					return file, nil
+				},
+			})
+			So(err, ShouldBeNil)
+			flushBuffer, _, err := buffer.NewBuffer[parquetEvent](context.Background(), parquetContainer, buffer.Config{
+				ID:            "async-buffer",
+				FlushInterval: time.Millisecond,
+				Registry:      buffer.NewRegistry(),
+			})
+			So(err, ShouldBeNil)
+			for i := 0; i < events; i++ {
+				flushBuffer.Put(parquetEvent{ID: int64(i), Name: "event"})
+			}
+			So(flushBuffer.Shutdown(context.Background()), ShouldBeNil)
+			So(flushBuffer.Stats().LastFlushError, ShouldBeNil)
+			So(parquetContainer.Len(), ShouldEqual, 0)
+			So(files, ShouldNotBeEmpty)
+			So(files[len(files)-1].closed, ShouldBeTrue)
+		})
+
+		Convey("RedisContainer should add every element", func() {
+			standIn := newRedisStandIn()
+			defer standIn.listener.Close()
+			redisContainer, err := container.NewRedisContainer(container.RedisConfig[objectEvent]{
+				ID:          "async-buffer",
+				FlushSize:   7,
+				Addr:        standIn.listener.Addr().String(),
+				KeyTemplate: "stream:{buffer_id}",
+				Fields: func(event objectEvent) (map[string]string, error) {
+					return map[string]string{"id": strconv.Itoa(event.ID)}, nil
+				},
+			})
+			So(err, ShouldBeNil)
+			putAsync(redisContainer, events)
+			redisContainer.Close()
+			So(standIn.received(), ShouldHaveLength, events)
+		})
+
+		Convey("NATSContainer should publish every element", func() {
+			standIn := newNATSStandIn()
+			defer standIn.listener.Close()
+			natsContainer, err := container.NewNATSContainer(container.NATSConfig[objectEvent]{
+				ID:              "async-buffer",
+				FlushSize:       7,
+				URL:             "nats://" + standIn.listener.Addr().String(),
+				SubjectTemplate: "events.{buffer_id}",
+			})
+			So(err, ShouldBeNil)
+			putAsync(natsContainer, events)
+			natsContainer.Close()
+			So(standIn.published(), ShouldHaveLength, events)
+		})
+	})
+}
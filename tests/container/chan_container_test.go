@@ -0,0 +1,106 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	buffer "github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestChanContainer(t *testing.T) {
+	Convey("Given a channel without receiver", t, func() {
+		batches := make(chan []int)
+
+		Convey("When flushing in ChanDrop mode", func() {
+			chanContainer, err := container.NewChanContainer(container.ChanConfig[int]{FlushSize: 2, Chan: batches, Mode: container.ChanDrop})
+			So(err, ShouldBeNil)
+			So(chanContainer.Put(1), ShouldBeNil)
+			err = chanContainer.Flush()
+
+			Convey("The batch should be dropped", func() {
+				So(errors.Is(err, container.ErrChanBatchDropped), ShouldBeTrue)
+				So(chanContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When flushing in ChanTimeout mode", func() {
+			chanContainer, err := container.NewChanContainer(container.ChanConfig[int]{FlushSize: 2, Chan: batches, Mode: container.ChanTimeout, Timeout: 10 * time.Millisecond})
+			So(err, ShouldBeNil)
+			So(chanContainer.Put(1), ShouldBeNil)
+			err = chanContainer.Flush()
+
+			Convey("The batch should be kept for next Flush", func() {
+				So(errors.Is(err, container.ErrChanTimeout), ShouldBeTrue)
+				So(chanContainer.Len(), ShouldEqual, 1)
+
+				received := make(chan []int, 1)
+				go func() { received <- <-batches }()
+				So(chanContainer.Put(2), ShouldBeNil)
+				So(chanContainer.Flush(), ShouldBeNil)
+				So(<-received, ShouldResemble, []int{1, 2})
+				So(chanContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When flushing in ChanTimeout mode inside a Buffer", func() {
+			chanContainer, err := container.NewChanContainer(container.ChanConfig[int]{FlushSize: 2, Chan: batches, Mode: container.ChanTimeout, Timeout: 10 * time.Millisecond})
+			So(err, ShouldBeNil)
+			chainedBuffer, errChan, err := buffer.NewBuffer[int](context.Background(), chanContainer, buffer.Config{
+				ID:               "chan-buffer",
+				DisableAutoFlush: true,
+				Registry:         buffer.NewRegistry(),
+			})
+			So(err, ShouldBeNil)
+			defer chainedBuffer.Close()
+			So(chainedBuffer.Put(1), ShouldBeNil)
+			So(chainedBuffer.Flush(false), ShouldBeNil)
+
+			Convey("The batch should survive the failed flush and be sent by next flush", func() {
+				So(errors.Is(<-errChan, container.ErrChanTimeout), ShouldBeTrue)
+				So(chainedBuffer.Stats().ContainerLen, ShouldEqual, 1)
+
+				received := make(chan []int, 1)
+				go func() { received <- <-batches }()
+				So(chainedBuffer.Flush(false), ShouldBeNil)
+				So(<-received, ShouldResemble, []int{1})
+			})
+		})
+
+		Convey("When ChanTimeout mode has no Timeout", func() {
+			_, err := container.NewChanContainer(container.ChanConfig[int]{FlushSize: 2, Chan: batches, Mode: container.ChanTimeout})
+
+			Convey("NewChanContainer should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given a Buffer chained into a worker by ChanContainer", t, func() {
+		batches := make(chan []int, 1)
+		chanContainer, err := container.NewChanContainer(container.ChanConfig[int]{FlushSize: 3, Chan: batches})
+		So(err, ShouldBeNil)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		chainedBuffer, _, err := buffer.NewBuffer[int](ctx, chanContainer, buffer.Config{FlushInterval: time.Hour, SyncAutoFlush: true})
+		So(err, ShouldBeNil)
+
+		Convey("When the container is full", func() {
+			for i := 0; i < 3; i++ {
+				So(chainedBuffer.Put(i), ShouldBeNil)
+			}
+
+			Convey("The worker should receive the batch", func() {
+				select {
+				case batch := <-batches:
+					So(batch, ShouldResemble, []int{0, 1, 2})
+				case <-time.After(time.Second):
+					So("timeout", ShouldBeEmpty)
+				}
+			})
+		})
+	})
+}
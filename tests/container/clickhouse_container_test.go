@@ -0,0 +1,143 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go"
+	"github.com/ClickHouse/ch-go/chpool"
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClickHouseContainerRetryQueue(t *testing.T) {
+	Convey("Given a ClickHouseContainer connected to an unreachable server", t, func() {
+		pool, err := chpool.New(context.Background(), chpool.Options{
+			ClientOptions: ch.Options{Address: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond},
+		})
+		So(err, ShouldBeNil)
+		defer pool.Close()
+
+		chContainer, err := container.NewClickHouseContainer(pool, "events", 2, newRoutedInput, container.WithRetryQueue(2))
+		So(err, ShouldBeNil)
+
+		Convey("Failed blocks should be kept in the retry queue", func() {
+			So(chContainer.Put(routedRow{id: 1}), ShouldBeNil)
+			So(chContainer.Flush(), ShouldNotBeNil)
+			So(chContainer.RetryQueueLen(), ShouldEqual, 1)
+			So(chContainer.Len(), ShouldEqual, 0)
+
+			chContainer.Reset()
+			So(chContainer.RetryQueueLen(), ShouldEqual, 1)
+
+			Convey("Flush without new rows should send the queued blocks again", func() {
+				So(chContainer.Flush(), ShouldNotBeNil)
+				So(chContainer.RetryQueueLen(), ShouldEqual, 1)
+			})
+
+			Convey("The oldest blocks should be dropped when the queue overflows", func() {
+				So(chContainer.Put(routedRow{id: 2}), ShouldBeNil)
+				So(chContainer.Flush(), ShouldNotBeNil)
+				So(chContainer.RetryQueueLen(), ShouldEqual, 2)
+
+				So(chContainer.Put(routedRow{id: 3}), ShouldBeNil)
+				err := chContainer.Flush()
+				So(errors.Is(err, container.ErrClickHouseRetryQueueFull), ShouldBeTrue)
+				So(chContainer.RetryQueueLen(), ShouldEqual, 2)
+			})
+		})
+	})
+}
+
+func TestClickHouseInsertQuery(t *testing.T) {
+	newInput := func() proto.Input {
+		return proto.Input{{Name: "id", Data: &proto.ColUInt64{}}}
+	}
+	newContainer := func(opts ...container.ClickHouseOption) *container.ClickHouseContainer {
+		chContainer, err := container.NewClickHouseContainer(nil, "events", 10, newInput, opts...)
+		So(err, ShouldBeNil)
+		return chContainer
+	}
+
+	Convey("Given a ClickHouseContainer without options", t, func() {
+		query := newContainer().InsertQuery(newInput(), 1)
+
+		Convey("The insert query should carry no settings and a random query id", func() {
+			So(query.Body, ShouldEqual, `INSERT INTO "events" ("id") VALUES`)
+			So(query.Settings, ShouldBeEmpty)
+			So(query.QueryID, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a ClickHouseContainer with insert settings, quorum, deduplication token and query id prefix", t, func() {
+		chContainer := newContainer(
+			container.WithInsertSettings(ch.SettingInt("max_insert_block_size", 1048576)),
+			container.WithInsertQuorum("auto"),
+			container.WithDeduplicationToken("writer-1"),
+			container.WithQueryIDPrefix("events-writer"),
+		)
+
+		Convey("Settings, token and query id should be derived from the instance and the sequence number of block", func() {
+			query := chContainer.InsertQuery(newInput(), 7)
+			So(query.Settings, ShouldHaveLength, 3)
+			So(query.Settings[:2], ShouldResemble, []ch.Setting{
+				ch.SettingInt("max_insert_block_size", 1048576),
+				{Key: "insert_quorum", Value: "auto"},
+			})
+			So(query.Settings[2].Key, ShouldEqual, "insert_deduplication_token")
+			So(regexp.MustCompile("^writer-1-[0-9a-f]{32}-7$").MatchString(query.Settings[2].Value), ShouldBeTrue)
+			instance := strings.TrimSuffix(strings.TrimPrefix(query.Settings[2].Value, "writer-1-"), "-7")
+			So(query.QueryID, ShouldEqual, "events-writer-"+instance+"-7")
+
+			Convey("And a block sent again should get the same token", func() {
+				So(chContainer.InsertQuery(newInput(), 7).Settings[2].Value, ShouldEqual, query.Settings[2].Value)
+			})
+
+			Convey("And another block should get its own token while the configured settings stay untouched", func() {
+				query := chContainer.InsertQuery(newInput(), 8)
+				So(query.Settings, ShouldHaveLength, 3)
+				So(query.Settings[2].Value, ShouldEqual, "writer-1-"+instance+"-8")
+				So(query.QueryID, ShouldEqual, "events-writer-"+instance+"-8")
+			})
+
+			Convey("And another container(e.g. after a restart) should never reuse the token", func() {
+				restarted := newContainer(container.WithDeduplicationToken("writer-1"), container.WithQueryIDPrefix("events-writer"))
+				query := restarted.InsertQuery(newInput(), 7)
+				So(query.Settings[0].Value, ShouldStartWith, "writer-1-")
+				So(query.Settings[0].Value, ShouldNotContainSubstring, instance)
+				So(query.QueryID, ShouldNotContainSubstring, instance)
+			})
+		})
+	})
+
+	Convey("Given a ClickHouseContainer with async insert and deduplication token", t, func() {
+		query := newContainer(container.WithAsyncInsert(true), container.WithDeduplicationToken("")).InsertQuery(newInput(), 3)
+
+		Convey("Async insert should be waited and deduplicated with the instance as token prefix", func() {
+			So(query.Settings, ShouldHaveLength, 4)
+			So(query.Settings[:2], ShouldResemble, []ch.Setting{
+				ch.SettingInt("async_insert", 1),
+				ch.SettingInt("wait_for_async_insert", 1),
+			})
+			So(query.Settings[2].Key, ShouldEqual, "insert_deduplication_token")
+			So(regexp.MustCompile("^[0-9a-f]{32}-3$").MatchString(query.Settings[2].Value), ShouldBeTrue)
+			So(query.Settings[3], ShouldResemble, ch.SettingInt("async_insert_deduplicate", 1))
+		})
+	})
+
+	Convey("Given a ClickHouseContainer with async insert not waited", t, func() {
+		query := newContainer(container.WithAsyncInsert(false)).InsertQuery(newInput(), 1)
+
+		Convey("The insert should return before the data is flushed by server", func() {
+			So(query.Settings, ShouldResemble, []ch.Setting{
+				ch.SettingInt("async_insert", 1),
+				ch.SettingInt("wait_for_async_insert", 0),
+			})
+		})
+	})
+}
@@ -0,0 +1,153 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go"
+	"github.com/ClickHouse/ch-go/chpool"
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/Kevinello/go-buffer/container"
+	"github.com/google/uuid"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type clickHouseEvent struct {
+	ID        uint64    `ch:"id"`
+	Service   string    `ch:"service,lowcardinality"`
+	Message   string    `ch:"message"`
+	Latency   float64   `ch:"latency"`
+	Success   bool      `ch:"success"`
+	Timestamp time.Time `ch:"timestamp,datetime64=3"`
+	TraceID   uuid.UUID `ch:"trace_id"`
+	UserID    *int32    `ch:"user_id"`
+	Tags      []string  `ch:"tags,lowcardinality"`
+	Internal  string    `ch:"-"`
+	Level     int8
+}
+
+func TestClickHouseMapper(t *testing.T) {
+	Convey("Given a ClickHouseMapper of a tagged struct", t, func() {
+		mapper, err := container.NewClickHouseMapper[clickHouseEvent]()
+		So(err, ShouldBeNil)
+
+		Convey("Columns should be derived from struct tags", func() {
+			So(mapper.Columns(), ShouldResemble, []container.ClickHouseColumn{
+				{Name: "id", Type: "UInt64"},
+				{Name: "service", Type: "LowCardinality(String)"},
+				{Name: "message", Type: "String"},
+				{Name: "latency", Type: "Float64"},
+				{Name: "success", Type: "Bool"},
+				{Name: "timestamp", Type: "DateTime64(3)"},
+				{Name: "trace_id", Type: "UUID"},
+				{Name: "user_id", Type: "Nullable(Int32)"},
+				{Name: "tags", Type: "Array(LowCardinality(String))"},
+				{Name: "Level", Type: "Int8"},
+			})
+		})
+
+		Convey("When appending rows into an input", func() {
+			input := mapper.NewInput()
+			userID := int32(42)
+			now := time.UnixMilli(1700000000123)
+			So(mapper.Append(input, clickHouseEvent{ID: 1, Service: "api", Timestamp: now, UserID: &userID, Tags: []string{"a", "b"}}), ShouldBeNil)
+			So(mapper.Row(clickHouseEvent{ID: 2, Service: "api", Level: -1}).Insert(input), ShouldBeNil)
+
+			Convey("Every column should hold the values", func() {
+				So(input[0].Data.Rows(), ShouldEqual, 2)
+				So(input[0].Data.(*proto.ColUInt64).Row(1), ShouldEqual, 2)
+				So(input[1].Data.(*proto.ColLowCardinality[string]).Row(0), ShouldEqual, "api")
+				So(input[5].Data.(*proto.ColDateTime64).Row(0).UnixMilli(), ShouldEqual, now.UnixMilli())
+				userIDs := input[7].Data.(*proto.ColNullable[int32])
+				So(userIDs.Row(0), ShouldResemble, proto.NewNullable[int32](42))
+				So(userIDs.Row(1).IsSet(), ShouldBeFalse)
+				So(input[8].Data.(*proto.ColArr[string]).Row(0), ShouldResemble, []string{"a", "b"})
+				So(input[9].Data.(*proto.ColInt8).Row(1), ShouldEqual, -1)
+			})
+		})
+
+		Convey("When validating against table schema", func() {
+			schema := []container.ClickHouseColumn{
+				{Name: "id", Type: "UInt64"},
+				{Name: "service", Type: "LowCardinality(String)"},
+				{Name: "message", Type: "String"},
+				{Name: "latency", Type: "Float64"},
+				{Name: "success", Type: "Bool"},
+				{Name: "timestamp", Type: "DateTime64(3, 'UTC')"},
+				{Name: "trace_id", Type: "UUID"},
+				{Name: "user_id", Type: "Nullable(Int32)"},
+				{Name: "tags", Type: "Array(LowCardinality(String))"},
+				{Name: "Level", Type: "Int8"},
+				{Name: "date", Type: "Date", DefaultKind: "MATERIALIZED"},
+			}
+
+			Convey("A matched schema should pass", func() {
+				So(mapper.Validate(schema), ShouldBeNil)
+			})
+
+			Convey("Mismatches should be reported", func() {
+				schema[2].Type = "LowCardinality(String)"
+				schema = schema[:len(schema)-2]
+				schema[0].DefaultKind = "ALIAS"
+				err := mapper.Validate(schema)
+
+				var schemaErr *container.ClickHouseSchemaError
+				So(errors.As(err, &schemaErr), ShouldBeTrue)
+				So(schemaErr.Missing, ShouldResemble, []string{"Level"})
+				So(schemaErr.NotInsertable, ShouldResemble, []string{"id"})
+				So(schemaErr.Mismatched, ShouldResemble, []container.ClickHouseColumnDiff{
+					{Name: "message", TableType: "LowCardinality(String)", InputType: "String"},
+				})
+				So(err.Error(), ShouldContainSubstring, "column message is LowCardinality(String) in table, but String in input")
+			})
+		})
+	})
+
+	Convey("Given a struct with unsupported field", t, func() {
+		type invalidRow struct {
+			Payload map[string]string `ch:"payload"`
+		}
+		_, err := container.NewClickHouseMapper[invalidRow]()
+
+		Convey("NewClickHouseMapper should fail", func() {
+			So(err, ShouldNotBeNil)
+			So(errors.Unwrap(err), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestClickHouseMappedContainer(t *testing.T) {
+	Convey("Given a ClickHouseMappedContainer connected to an unreachable server", t, func() {
+		pool, err := chpool.New(context.Background(), chpool.Options{
+			ClientOptions: ch.Options{Address: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond},
+		})
+		So(err, ShouldBeNil)
+		defer pool.Close()
+
+		Convey("The schema validation should fail", func() {
+			_, err := container.NewClickHouseMappedContainer[clickHouseEvent](context.Background(), pool, "events", 2)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When the schema validation is skipped and the retry queue holds one block", func() {
+			mappedContainer, err := container.NewClickHouseMappedContainer[clickHouseEvent](context.Background(), pool, "events", 2,
+				container.WithSchemaValidation(nil), container.WithRetryQueue(1))
+			So(err, ShouldBeNil)
+			So(mappedContainer.Put(clickHouseEvent{ID: 1}), ShouldBeNil)
+			So(mappedContainer.Len(), ShouldEqual, 1)
+
+			Convey("Failed blocks should be kept in the retry queue by options", func() {
+				So(mappedContainer.Flush(), ShouldNotBeNil)
+				So(mappedContainer.Len(), ShouldEqual, 0)
+				So(mappedContainer.RetryQueueLen(), ShouldEqual, 1)
+
+				So(mappedContainer.Put(clickHouseEvent{ID: 2}), ShouldBeNil)
+				err := mappedContainer.Flush()
+				So(errors.Is(err, container.ErrClickHouseRetryQueueFull), ShouldBeTrue)
+				So(mappedContainer.RetryQueueLen(), ShouldEqual, 1)
+			})
+		})
+	})
+}
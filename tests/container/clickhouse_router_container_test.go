@@ -0,0 +1,72 @@
+package container
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type routedRow struct {
+	table string
+	id    uint64
+}
+
+func (row routedRow) Table() string { return row.table }
+
+func (row routedRow) Insert(input proto.Input) error {
+	input[0].Data.(*proto.ColUInt64).Append(row.id)
+	return nil
+}
+
+func newRoutedInput() proto.Input {
+	return proto.Input{{Name: "id", Data: new(proto.ColUInt64)}}
+}
+
+func TestClickHouseRouterContainer(t *testing.T) {
+	Convey("Given a ClickHouseRouterContainer of two tables", t, func() {
+		router, err := container.NewClickHouseRouterContainer(nil, 2, map[string]func() proto.Input{
+			"events":  newRoutedInput,
+			"metrics": newRoutedInput,
+		})
+		So(err, ShouldBeNil)
+
+		Convey("Rows should be routed into the block of their table", func() {
+			So(router.Put(routedRow{table: "events", id: 1}), ShouldBeNil)
+			So(router.Put(routedRow{table: "metrics", id: 2}), ShouldBeNil)
+			So(router.TableLen("events"), ShouldEqual, 1)
+			So(router.TableLen("metrics"), ShouldEqual, 1)
+			So(router.Len(), ShouldEqual, 2)
+			So(router.IsFull(), ShouldBeFalse)
+
+			So(router.Put(routedRow{table: "events", id: 3}), ShouldBeNil)
+			So(router.IsFull(), ShouldBeTrue)
+
+			router.Reset()
+			So(router.TableLen("events"), ShouldEqual, 0)
+			So(router.Len(), ShouldEqual, 0)
+			So(router.IsFull(), ShouldBeFalse)
+		})
+
+		Convey("Rows of unknown table should be rejected", func() {
+			So(router.Put(routedRow{table: "logs", id: 1}), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given a ClickHouseRouterError", t, func() {
+		insertErr := errors.New("connection refused")
+		routerErr := &container.ClickHouseRouterError{Failed: map[string]error{"metrics": insertErr, "events": insertErr}}
+
+		Convey("Failed tables should be sorted and errors unwrapped", func() {
+			So(routerErr.Tables(), ShouldResemble, []string{"events", "metrics"})
+			So(errors.Is(routerErr, insertErr), ShouldBeTrue)
+		})
+	})
+
+	Convey("Creating a ClickHouseRouterContainer without tables should fail", t, func() {
+		_, err := container.NewClickHouseRouterContainer(nil, 2, nil)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,97 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiffClickHouseSchema(t *testing.T) {
+	tableColumns := []container.ClickHouseColumn{
+		{Name: "id", Type: "UInt64"},
+		{Name: "name", Type: "LowCardinality(String)"},
+		{Name: "created", Type: "DateTime('Asia/Shanghai')"},
+		{Name: "updated", Type: "DateTime64(3, 'UTC')"},
+		{Name: "day", Type: "Date", DefaultKind: "MATERIALIZED"},
+		{Name: "upper_name", Type: "String", DefaultKind: "ALIAS"},
+		{Name: "score", Type: "Float64", DefaultKind: "DEFAULT"},
+	}
+
+	Convey("Given the columns of a ClickHouse table", t, func() {
+		for _, testCase := range []struct {
+			name     string
+			columns  []container.ClickHouseColumn
+			expected *container.ClickHouseSchemaError
+		}{
+			{
+				name:    "matched columns",
+				columns: []container.ClickHouseColumn{{Name: "id", Type: "UInt64"}, {Name: "name", Type: "LowCardinality(String)"}, {Name: "score", Type: "Float64"}},
+			},
+			{
+				name:    "timezone of DateTime is ignored",
+				columns: []container.ClickHouseColumn{{Name: "created", Type: "DateTime"}, {Name: "updated", Type: "DateTime64(3)"}},
+			},
+			{
+				name:    "timezone of DateTime differs between table and input",
+				columns: []container.ClickHouseColumn{{Name: "created", Type: "DateTime('UTC')"}, {Name: "updated", Type: "DateTime64(3,'Europe/Paris')"}},
+			},
+			{
+				name:     "precision of DateTime64 is not ignored",
+				columns:  []container.ClickHouseColumn{{Name: "updated", Type: "DateTime64(6)"}},
+				expected: &container.ClickHouseSchemaError{Table: "events", Mismatched: []container.ClickHouseColumnDiff{{Name: "updated", TableType: "DateTime64(3, 'UTC')", InputType: "DateTime64(6)"}}},
+			},
+			{
+				name:     "missing column",
+				columns:  []container.ClickHouseColumn{{Name: "id", Type: "UInt64"}, {Name: "email", Type: "String"}},
+				expected: &container.ClickHouseSchemaError{Table: "events", Missing: []string{"email"}},
+			},
+			{
+				name:     "materialized and alias columns",
+				columns:  []container.ClickHouseColumn{{Name: "day", Type: "Date"}, {Name: "upper_name", Type: "String"}},
+				expected: &container.ClickHouseSchemaError{Table: "events", NotInsertable: []string{"day", "upper_name"}},
+			},
+			{
+				name:    "type mismatches",
+				columns: []container.ClickHouseColumn{{Name: "id", Type: "UInt32"}, {Name: "name", Type: "String"}},
+				expected: &container.ClickHouseSchemaError{Table: "events", Mismatched: []container.ClickHouseColumnDiff{
+					{Name: "id", TableType: "UInt64", InputType: "UInt32"},
+					{Name: "name", TableType: "LowCardinality(String)", InputType: "String"},
+				}},
+			},
+			{
+				name:    "all kinds of differences",
+				columns: []container.ClickHouseColumn{{Name: "email", Type: "String"}, {Name: "day", Type: "Date"}, {Name: "score", Type: "Float32"}},
+				expected: &container.ClickHouseSchemaError{
+					Table:         "events",
+					Missing:       []string{"email"},
+					NotInsertable: []string{"day"},
+					Mismatched:    []container.ClickHouseColumnDiff{{Name: "score", TableType: "Float64", InputType: "Float32"}},
+				},
+			},
+		} {
+			Convey(testCase.name, func() {
+				So(container.DiffClickHouseSchema("events", testCase.columns, tableColumns), ShouldResemble, testCase.expected)
+			})
+		}
+	})
+
+	Convey("Timezones should be dropped from nested types only", t, func() {
+		tableColumns := []container.ClickHouseColumn{
+			{Name: "created", Type: "Nullable(DateTime('UTC'))"},
+			{Name: "updates", Type: "Array(DateTime64(9, 'UTC'))"},
+			{Name: "counters", Type: "Map(String, UInt64)"},
+			{Name: "label", Type: "LowCardinality(String)"},
+		}
+		columns := []container.ClickHouseColumn{
+			{Name: "created", Type: "Nullable(DateTime)"},
+			{Name: "updates", Type: "Array(DateTime64(9))"},
+			{Name: "counters", Type: "Map(String,UInt64)"},
+			{Name: "label", Type: "LowCardinality(Nullable(String))"},
+		}
+		So(container.DiffClickHouseSchema("events", columns, tableColumns), ShouldResemble, &container.ClickHouseSchemaError{
+			Table:      "events",
+			Mismatched: []container.ClickHouseColumnDiff{{Name: "label", TableType: "LowCardinality(String)", InputType: "LowCardinality(Nullable(String))"}},
+		})
+	})
+}
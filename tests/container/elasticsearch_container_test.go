@@ -0,0 +1,187 @@
+package container
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// bulkServer mimic the _bulk endpoint: document named "bad" is rejected with 400, "busy" is rejected with 429 on its first attempt
+type bulkServer struct {
+	requests int
+	actions  []map[string]map[string]string
+	indexed  []objectEvent
+	busy     map[int]bool
+}
+
+func (server *bulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	server.requests++
+	items := make([]map[string]any, 0)
+	hasErrors := false
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		action := map[string]map[string]string{}
+		json.Unmarshal(scanner.Bytes(), &action)
+		scanner.Scan()
+		event := objectEvent{}
+		json.Unmarshal(scanner.Bytes(), &event)
+		server.actions = append(server.actions, action)
+
+		result := map[string]any{"status": http.StatusCreated}
+		switch {
+		case event.Name == "bad":
+			result = map[string]any{"status": http.StatusBadRequest, "error": map[string]string{"type": "mapper_parsing_exception", "reason": "failed to parse"}}
+		case event.Name == "busy" && !server.busy[event.ID]:
+			server.busy[event.ID] = true
+			result = map[string]any{"status": http.StatusTooManyRequests, "error": map[string]string{"type": "es_rejected_execution_exception", "reason": "queue is full"}}
+		default:
+			server.indexed = append(server.indexed, event)
+		}
+		if result["status"] != http.StatusCreated {
+			hasErrors = true
+		}
+		items = append(items, map[string]any{"index": result})
+	}
+	json.NewEncoder(w).Encode(map[string]any{"took": 1, "errors": hasErrors, "items": items})
+}
+
+func TestElasticsearchBulkContainer(t *testing.T) {
+	Convey("Given an ElasticsearchBulkContainer and a bulk endpoint", t, func() {
+		bulk := &bulkServer{busy: map[int]bool{}}
+		server := httptest.NewServer(bulk)
+		defer server.Close()
+
+		esContainer, err := container.NewElasticsearchBulkContainer(container.ElasticsearchBulkConfig[objectEvent]{
+			ID:            "test-buffer",
+			FlushSize:     3,
+			URL:           server.URL,
+			IndexTemplate: "events-{buffer_id}-{date}",
+			DocumentID:    func(event objectEvent) string { return "doc-" + event.Name },
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When all documents are indexed", func() {
+			So(esContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+			So(esContainer.Put(objectEvent{ID: 2, Name: "b"}), ShouldBeNil)
+			So(esContainer.Flush(), ShouldBeNil)
+
+			Convey("Every document should be rendered with templated index and id", func() {
+				So(bulk.indexed, ShouldHaveLength, 2)
+				So(bulk.actions[0]["index"]["_index"], ShouldEqual, "events-test-buffer-"+time.Now().UTC().Format(time.DateOnly))
+				So(bulk.actions[1]["index"]["_id"], ShouldEqual, "doc-b")
+				So(esContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When some documents fail", func() {
+			So(esContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+			So(esContainer.Put(objectEvent{ID: 2, Name: "bad"}), ShouldBeNil)
+			So(esContainer.Put(objectEvent{ID: 3, Name: "busy"}), ShouldBeNil)
+			err := esContainer.Flush()
+
+			Convey("Retryable documents should be requeued and permanent failures should be reported", func() {
+				var partialErr *container.PartialFlushError[objectEvent]
+				So(errors.As(err, &partialErr), ShouldBeTrue)
+				So(partialErr.Total, ShouldEqual, 3)
+				So(partialErr.Failed, ShouldHaveLength, 2)
+				So(partialErr.Failed[0].Element.ID, ShouldEqual, 2)
+				So(partialErr.Failed[0].Requeued, ShouldBeFalse)
+				So(partialErr.Failed[1].Element.ID, ShouldEqual, 3)
+				So(partialErr.Failed[1].Requeued, ShouldBeTrue)
+
+				var itemErr *container.ElasticsearchItemError
+				So(errors.As(err, &itemErr), ShouldBeTrue)
+				So(itemErr.Type, ShouldEqual, "mapper_parsing_exception")
+				So(esContainer.Len(), ShouldEqual, 1)
+
+				Convey("And the requeued document should be indexed by next flush", func() {
+					So(esContainer.Flush(), ShouldBeNil)
+					So(bulk.requests, ShouldEqual, 2)
+					So(bulk.indexed, ShouldHaveLength, 2)
+					So(esContainer.Len(), ShouldEqual, 0)
+				})
+			})
+		})
+
+		Convey("When the container runs inside a Buffer", func() {
+			flushBuffer, errChan, err := buffer.NewBuffer[objectEvent](context.Background(), esContainer, buffer.Config{
+				ID:               "es-buffer",
+				DisableAutoFlush: true,
+				Registry:         buffer.NewRegistry(),
+			})
+			So(err, ShouldBeNil)
+			defer flushBuffer.Close()
+
+			So(flushBuffer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+			So(flushBuffer.Put(objectEvent{ID: 2, Name: "busy"}), ShouldBeNil)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+			var flushErr *buffer.FlushError
+			So(errors.As(<-errChan, &flushErr), ShouldBeTrue)
+			So(flushErr.Err.Error(), ShouldContainSubstring, "1 requeued")
+
+			Convey("The requeued document should survive the failed flush and be indexed by next flush", func() {
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 1)
+				So(flushBuffer.Flush(false), ShouldBeNil)
+				So(bulk.indexed, ShouldHaveLength, 2)
+				So(bulk.indexed[1].ID, ShouldEqual, 2)
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the bulk response can't be decoded", func() {
+			requests := 0
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Write([]byte("<html>proxy error</html>"))
+			})
+			So(esContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+			err := esContainer.Flush()
+
+			Convey("The documents may have been indexed, so they should not be requeued", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "fail to decode bulk response")
+				var partialErr *container.PartialFlushError[objectEvent]
+				So(errors.As(err, &partialErr), ShouldBeFalse)
+				So(esContainer.Len(), ShouldEqual, 0)
+				So(esContainer.Flush(), ShouldBeNil)
+				So(requests, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the cluster is unavailable", func() {
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			So(esContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+
+			Convey("The whole batch should be kept until MaxAttempts", func() {
+				var partialErr *container.PartialFlushError[objectEvent]
+				So(errors.As(esContainer.Flush(), &partialErr), ShouldBeTrue)
+				So(partialErr.Failed[0].Requeued, ShouldBeTrue)
+				So(esContainer.Len(), ShouldEqual, 1)
+
+				var statusErr *container.HTTPStatusError
+				So(errors.As(esContainer.Flush(), &statusErr), ShouldBeTrue)
+				So(statusErr.StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+				So(esContainer.Len(), ShouldEqual, 1)
+
+				So(errors.As(esContainer.Flush(), &partialErr), ShouldBeTrue)
+				So(partialErr.Failed[0].Requeued, ShouldBeFalse)
+				So(esContainer.Len(), ShouldEqual, 0)
+			})
+		})
+	})
+}
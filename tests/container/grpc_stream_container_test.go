@@ -0,0 +1,259 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// collectorServer handler type of the client-streaming service test.Collector/Upload
+type collectorServer interface {
+	upload(stream grpc.ServerStream) error
+}
+
+var collectorServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Collector",
+	HandlerType: (*collectorServer)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Upload",
+		Handler:       func(srv any, stream grpc.ServerStream) error { return srv.(collectorServer).upload(stream) },
+		ClientStreams: true,
+	}},
+}
+
+// collector receive events as StringValue and respond Empty when the client closes the stream.
+// the stream is terminated with Unavailable when receiving "break" while breaks is positive,
+// and with ResourceExhausted when receiving "reject"
+type collector struct {
+	mutex    sync.Mutex
+	streams  int
+	breaks   int
+	received []string
+	broken   chan struct{}
+}
+
+func (server *collector) upload(stream grpc.ServerStream) error {
+	server.mutex.Lock()
+	server.streams++
+	server.mutex.Unlock()
+	for {
+		message := &wrapperspb.StringValue{}
+		if err := stream.RecvMsg(message); errors.Is(err, io.EOF) {
+			return stream.SendMsg(&emptypb.Empty{})
+		} else if err != nil {
+			return err
+		}
+
+		server.mutex.Lock()
+		switch {
+		case message.Value == "break" && server.breaks > 0:
+			server.breaks--
+			server.mutex.Unlock()
+			server.broken <- struct{}{}
+			return status.Error(codes.Unavailable, "server is restarting")
+		case message.Value == "reject":
+			server.mutex.Unlock()
+			return status.Error(codes.ResourceExhausted, "quota exceeded")
+		}
+		server.received = append(server.received, message.Value)
+		server.mutex.Unlock()
+	}
+}
+
+func (server *collector) snapshot() (streams int, received []string) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.streams, append([]string{}, server.received...)
+}
+
+// uploadClient typed client stream, like the generated `pb.Collector_UploadClient`
+type uploadClient struct {
+	grpc.ClientStream
+}
+
+func (client uploadClient) Send(message *wrapperspb.StringValue) error {
+	return client.SendMsg(message)
+}
+
+func (client uploadClient) CloseAndRecv() error {
+	if err := client.CloseSend(); err != nil {
+		return err
+	}
+	return client.RecvMsg(&emptypb.Empty{})
+}
+
+func TestGRPCStreamContainer(t *testing.T) {
+	Convey("Given a GRPCStreamContainer uploading to a gRPC server over bufconn", t, func() {
+		server := &collector{broken: make(chan struct{}, 1)}
+		listener := bufconn.Listen(1 << 20)
+		grpcServer := grpc.NewServer()
+		grpcServer.RegisterService(&collectorServiceDesc, server)
+		go grpcServer.Serve(listener)
+		defer grpcServer.Stop()
+
+		conn, err := grpc.Dial("bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		So(err, ShouldBeNil)
+		defer conn.Close()
+
+		config := container.GRPCStreamConfig[string, *wrapperspb.StringValue]{
+			FlushSize: 3,
+			Open: func(ctx context.Context) (container.GRPCSendStream[*wrapperspb.StringValue], error) {
+				stream, err := conn.NewStream(ctx, &collectorServiceDesc.Streams[0], "/test.Collector/Upload")
+				if err != nil {
+					return nil, err
+				}
+				return uploadClient{stream}, nil
+			},
+			Messages: func(batch []string) (messages []*wrapperspb.StringValue, err error) {
+				for _, event := range batch {
+					messages = append(messages, wrapperspb.String(event))
+				}
+				return
+			},
+			CloseStream: func(stream container.GRPCSendStream[*wrapperspb.StringValue]) error {
+				return stream.(uploadClient).CloseAndRecv()
+			},
+			MaxReconnects:    1,
+			ReconnectBackoff: time.Millisecond,
+		}
+
+		Convey("When the stream works", func() {
+			grpcContainer, err := container.NewGRPCStreamContainer(config)
+			So(err, ShouldBeNil)
+			So(grpcContainer.Put("a"), ShouldBeNil)
+			So(grpcContainer.Put("b"), ShouldBeNil)
+			So(grpcContainer.Flush(), ShouldBeNil)
+			So(grpcContainer.Put("c"), ShouldBeNil)
+			So(grpcContainer.Flush(), ShouldBeNil)
+
+			Convey("Batches should be sent on the same stream and confirmed by Close", func() {
+				So(grpcContainer.Close(), ShouldBeNil)
+				streams, received := server.snapshot()
+				So(streams, ShouldEqual, 1)
+				So(received, ShouldResemble, []string{"a", "b", "c"})
+			})
+		})
+
+		Convey("When the server terminates the stream after a flush", func() {
+			responses := 0
+			config.Response = func() any {
+				responses++
+				return &emptypb.Empty{}
+			}
+			breakAfterFlush := func(config container.GRPCStreamConfig[string, *wrapperspb.StringValue]) {
+				grpcContainer, err := container.NewGRPCStreamContainer(config)
+				So(err, ShouldBeNil)
+				server.breaks = 1
+				So(grpcContainer.Put("a"), ShouldBeNil)
+				So(grpcContainer.Put("break"), ShouldBeNil)
+				So(grpcContainer.Put("lost"), ShouldBeNil)
+				// messages are only queued by Send, so the flush succeeds before the server terminates the stream
+				So(grpcContainer.Flush(), ShouldBeNil)
+				<-server.broken
+				// wait for the client to receive the status
+				time.Sleep(100 * time.Millisecond)
+
+				So(grpcContainer.Put("b"), ShouldBeNil)
+				So(grpcContainer.Flush(), ShouldBeNil)
+				So(grpcContainer.Close(), ShouldBeNil)
+			}
+
+			Convey("The next flush should reopen the stream and send the batch in flight again ahead of its own", func() {
+				breakAfterFlush(config)
+				streams, received := server.snapshot()
+				So(streams, ShouldEqual, 2)
+				So(received, ShouldResemble, []string{"a", "a", "break", "lost", "b"})
+				So(responses, ShouldEqual, 1)
+			})
+
+			Convey("With DisableResend, the batch in flight should be delivered at most once", func() {
+				config.DisableResend = true
+				breakAfterFlush(config)
+				streams, received := server.snapshot()
+				So(streams, ShouldEqual, 2)
+				So(received, ShouldResemble, []string{"a", "b"})
+			})
+		})
+
+		Convey("When the stream is confirmed by Close", func() {
+			grpcContainer, err := container.NewGRPCStreamContainer(config)
+			So(err, ShouldBeNil)
+			So(grpcContainer.Put("a"), ShouldBeNil)
+			So(grpcContainer.Flush(), ShouldBeNil)
+			So(grpcContainer.Close(), ShouldBeNil)
+			So(grpcContainer.Put("b"), ShouldBeNil)
+			So(grpcContainer.Flush(), ShouldBeNil)
+			So(grpcContainer.Close(), ShouldBeNil)
+
+			Convey("The confirmed batch should not be sent again on the next stream", func() {
+				streams, received := server.snapshot()
+				So(streams, ShouldEqual, 2)
+				So(received, ShouldResemble, []string{"a", "b"})
+			})
+		})
+
+		Convey("When every flush waits for the response of server", func() {
+			config.CloseEveryFlush = true
+			grpcContainer, err := container.NewGRPCStreamContainer(config)
+			So(err, ShouldBeNil)
+			server.breaks = 1
+			So(grpcContainer.Put("a"), ShouldBeNil)
+			So(grpcContainer.Put("break"), ShouldBeNil)
+			So(grpcContainer.Flush(), ShouldBeNil)
+
+			Convey("The batch broken in flight should be sent again on a new stream", func() {
+				streams, received := server.snapshot()
+				So(streams, ShouldEqual, 2)
+				So(received, ShouldResemble, []string{"a", "a", "break"})
+			})
+
+			Convey("A batch rejected by server should be kept with the status of server", func() {
+				So(grpcContainer.Put("reject"), ShouldBeNil)
+				err := grpcContainer.Flush()
+				So(status.Code(err), ShouldEqual, codes.ResourceExhausted)
+				var keptErr *container.KeptError
+				So(errors.As(err, &keptErr), ShouldBeTrue)
+				So(grpcContainer.Len(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the container runs inside a Buffer and the server is down", func() {
+			config.CloseEveryFlush = true
+			grpcContainer, err := container.NewGRPCStreamContainer(config)
+			So(err, ShouldBeNil)
+			flushBuffer, errChan, err := buffer.NewBuffer[string](context.Background(), grpcContainer, buffer.Config{
+				ID:               "grpc-buffer",
+				DisableAutoFlush: true,
+				Registry:         buffer.NewRegistry(),
+			})
+			So(err, ShouldBeNil)
+			defer flushBuffer.Close()
+
+			grpcServer.Stop()
+			So(flushBuffer.Put("a"), ShouldBeNil)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+
+			Convey("The batch should be kept for next flush", func() {
+				So(status.Code(<-errChan), ShouldEqual, codes.Unavailable)
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 1)
+			})
+		})
+	})
+}
@@ -0,0 +1,238 @@
+package container
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHTTPContainer(t *testing.T) {
+	Convey("Given a HTTPContainer posting gzipped NDJSON to a webhook", t, func() {
+		var (
+			requests int32
+			status   = []int{http.StatusOK}
+			header   http.Header
+			body     []byte
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := int(atomic.AddInt32(&requests, 1))
+			header = r.Header.Clone()
+			reader, err := gzip.NewReader(r.Body)
+			if err == nil {
+				body, _ = io.ReadAll(reader)
+			}
+			code := status[len(status)-1]
+			if n <= len(status) {
+				code = status[n-1]
+			}
+			if code == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(code)
+		}))
+		defer server.Close()
+
+		httpContainer, err := container.NewHTTPContainer(container.HTTPConfig[objectEvent]{
+			FlushSize:    2,
+			URL:          server.URL,
+			Header:       http.Header{"Authorization": []string{"Bearer token"}},
+			Encoder:      container.JSONLEncoder[objectEvent]{},
+			Gzip:         true,
+			MaxRetries:   2,
+			RetryBackoff: time.Millisecond,
+		})
+		So(err, ShouldBeNil)
+		So(httpContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+		So(httpContainer.Put(objectEvent{ID: 2, Name: "b"}), ShouldBeNil)
+
+		Convey("When the webhook accepts the batch", func() {
+			So(httpContainer.Flush(), ShouldBeNil)
+
+			Convey("The batch should be sent as one request with headers", func() {
+				So(atomic.LoadInt32(&requests), ShouldEqual, 1)
+				So(header.Get("Authorization"), ShouldEqual, "Bearer token")
+				So(header.Get("Content-Type"), ShouldEqual, "application/x-ndjson")
+				So(header.Get("Content-Encoding"), ShouldEqual, "gzip")
+				So(string(body), ShouldEqual, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n")
+				So(httpContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the webhook is throttling and then recovers", func() {
+			status = []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK}
+			So(httpContainer.Flush(), ShouldBeNil)
+
+			Convey("The batch should be retried until success", func() {
+				So(atomic.LoadInt32(&requests), ShouldEqual, 3)
+				So(httpContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the webhook keeps failing", func() {
+			status = []int{http.StatusBadGateway}
+			err := httpContainer.Flush()
+
+			Convey("The batch should be kept after retries are exhausted", func() {
+				So(err, ShouldNotBeNil)
+				So(atomic.LoadInt32(&requests), ShouldEqual, 3)
+				So(httpContainer.Len(), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the container runs inside a Buffer and the webhook fails then recovers", func() {
+			flushBuffer, errChan, err := buffer.NewBuffer[objectEvent](context.Background(), httpContainer, buffer.Config{
+				ID:               "http-buffer",
+				DisableAutoFlush: true,
+				Registry:         buffer.NewRegistry(),
+			})
+			So(err, ShouldBeNil)
+			defer flushBuffer.Close()
+
+			status = []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK}
+			So(flushBuffer.Flush(false), ShouldBeNil)
+			So(<-errChan, ShouldNotBeNil)
+
+			Convey("The kept batch should survive the failed flush and be sent by next flush", func() {
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 2)
+				So(flushBuffer.Flush(false), ShouldBeNil)
+				So(atomic.LoadInt32(&requests), ShouldEqual, 4)
+				So(string(body), ShouldEqual, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n")
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the webhook rejects the batch with 400", func() {
+			status = []int{http.StatusBadRequest}
+			err := httpContainer.Flush()
+
+			Convey("The batch should not be retried", func() {
+				var statusErr *container.HTTPStatusError
+				So(errors.As(err, &statusErr), ShouldBeTrue)
+				So(statusErr.StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(atomic.LoadInt32(&requests), ShouldEqual, 1)
+				So(httpContainer.Len(), ShouldEqual, 0)
+			})
+		})
+	})
+
+	Convey("Given a HTTPContainer handling partial failure from response", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var events []objectEvent
+			json.NewDecoder(r.Body).Decode(&events)
+			failed := make([]int, 0)
+			for i, event := range events {
+				if event.Name == "" {
+					failed = append(failed, i)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string][]int{"failed": failed})
+		}))
+		defer server.Close()
+
+		httpContainer, err := container.NewHTTPContainer(container.HTTPConfig[objectEvent]{
+			FlushSize: 3,
+			URL:       server.URL,
+			HandleResponse: func(response *http.Response, body []byte, batch []objectEvent) (map[int]error, error) {
+				var result struct {
+					Failed []int `json:"failed"`
+				}
+				if err := json.Unmarshal(body, &result); err != nil {
+					return nil, err
+				}
+				rejected := make(map[int]error)
+				for _, index := range result.Failed {
+					rejected[index] = errors.New("name is required")
+				}
+				return rejected, nil
+			},
+			RequeueRejected: true,
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When part of the batch is rejected", func() {
+			So(httpContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+			So(httpContainer.Put(objectEvent{ID: 2}), ShouldBeNil)
+			So(httpContainer.Put(objectEvent{ID: 3, Name: "c"}), ShouldBeNil)
+			err := httpContainer.Flush()
+
+			Convey("Flush should return PartialFlushError and requeue rejected elements", func() {
+				var partialErr *container.PartialFlushError[objectEvent]
+				So(errors.As(err, &partialErr), ShouldBeTrue)
+				So(partialErr.Total, ShouldEqual, 3)
+				So(partialErr.Failed, ShouldHaveLength, 1)
+				So(partialErr.Failed[0].Element.ID, ShouldEqual, 2)
+				So(partialErr.Requeued(), ShouldEqual, 1)
+				So(httpContainer.Len(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When part of the batch is rejected inside a Buffer", func() {
+			flushBuffer, errChan, err := buffer.NewBuffer[objectEvent](context.Background(), httpContainer, buffer.Config{
+				ID:               "http-buffer",
+				DisableAutoFlush: true,
+				Registry:         buffer.NewRegistry(),
+			})
+			So(err, ShouldBeNil)
+			defer flushBuffer.Close()
+
+			So(flushBuffer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+			So(flushBuffer.Put(objectEvent{ID: 2}), ShouldBeNil)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+			So(<-errChan, ShouldNotBeNil)
+
+			Convey("The rejected element should survive the failed flush", func() {
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 1)
+			})
+		})
+	})
+
+	Convey("Given a HTTPContainer whose response handler fails", t, func() {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+		}))
+		defer server.Close()
+
+		newContainer := func(handleErr error) *container.HTTPContainer[objectEvent] {
+			httpContainer, err := container.NewHTTPContainer(container.HTTPConfig[objectEvent]{
+				FlushSize:    3,
+				URL:          server.URL,
+				MaxRetries:   1,
+				RetryBackoff: time.Millisecond,
+				HandleResponse: func(response *http.Response, body []byte, batch []objectEvent) (map[int]error, error) {
+					return nil, handleErr
+				},
+			})
+			So(err, ShouldBeNil)
+			So(httpContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+			return httpContainer
+		}
+
+		Convey("A retryable error should retry the batch and keep it after retries", func() {
+			httpContainer := newContainer(&container.HTTPStatusError{StatusCode: http.StatusServiceUnavailable})
+			err := httpContainer.Flush()
+			var keptErr *container.KeptError
+			So(errors.As(err, &keptErr), ShouldBeTrue)
+			So(atomic.LoadInt32(&requests), ShouldEqual, 2)
+			So(httpContainer.Len(), ShouldEqual, 1)
+		})
+
+		Convey("Other errors should fail the batch without retry", func() {
+			httpContainer := newContainer(errors.New("malformed response"))
+			So(httpContainer.Flush(), ShouldNotBeNil)
+			So(atomic.LoadInt32(&requests), ShouldEqual, 1)
+			So(httpContainer.Len(), ShouldEqual, 0)
+		})
+	})
+}
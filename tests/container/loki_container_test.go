@@ -0,0 +1,166 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	"github.com/klauspost/compress/snappy"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// lokiPushedStream a decoded stream of PushRequest
+type lokiPushedStream struct {
+	labels string
+	lines  []string
+	nanos  []int64
+}
+
+func decodeLokiPushRequest(body []byte) (streams []lokiPushedStream) {
+	request, err := parseProto(body)
+	if err != nil {
+		panic(err)
+	}
+	for _, streamField := range otlpFields(request, 1) {
+		fields, _ := parseProto(streamField.Bytes)
+		stream := lokiPushedStream{labels: string(otlpFields(fields, 1)[0].Bytes)}
+		for _, entryField := range otlpFields(fields, 2) {
+			entry, _ := parseProto(entryField.Bytes)
+			timestamp := otlpField(entry, 1)
+			nanos := int64(otlpFields(timestamp, 1)[0].Value) * int64(time.Second)
+			if fractions := otlpFields(timestamp, 2); len(fractions) > 0 {
+				nanos += int64(fractions[0].Value)
+			}
+			stream.nanos = append(stream.nanos, nanos)
+			stream.lines = append(stream.lines, string(otlpFields(entry, 2)[0].Bytes))
+		}
+		streams = append(streams, stream)
+	}
+	return
+}
+
+func TestLokiContainer(t *testing.T) {
+	Convey("Given a Loki stand-in", t, func() {
+		var (
+			status   = http.StatusNoContent
+			response string
+			header   http.Header
+			streams  []lokiPushedStream
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Clone()
+			compressed, _ := io.ReadAll(r.Body)
+			body, err := snappy.Decode(nil, compressed)
+			if err != nil || r.URL.Path != "/loki/api/v1/push" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			streams = decodeLokiPushRequest(body)
+			w.WriteHeader(status)
+			io.WriteString(w, response)
+		}))
+		defer server.Close()
+
+		base := time.Unix(1700000000, 0).UTC()
+		lokiContainer, err := container.NewLokiContainer(container.LokiConfig[objectEvent]{
+			FlushSize: 4,
+			URL:       server.URL,
+			TenantID:  "tenant-a",
+			Labels: func(event objectEvent) map[string]string {
+				return map[string]string{"app": event.Name, "env": "test"}
+			},
+			Entry: func(event objectEvent) container.LokiEntry {
+				return container.LokiEntry{Timestamp: base.Add(time.Duration(event.ID) * time.Millisecond), Line: fmt.Sprintf("event %d", event.ID)}
+			},
+		})
+		So(err, ShouldBeNil)
+		So(lokiContainer.Put(objectEvent{ID: 3, Name: "api"}), ShouldBeNil)
+		So(lokiContainer.Put(objectEvent{ID: 1, Name: "api"}), ShouldBeNil)
+		So(lokiContainer.Put(objectEvent{ID: 2, Name: "worker"}), ShouldBeNil)
+		So(lokiContainer.Put(objectEvent{ID: 0, Name: "worker"}), ShouldBeNil)
+		So(lokiContainer.IsFull(), ShouldBeTrue)
+
+		Convey("When Loki accepts the push", func() {
+			So(lokiContainer.Flush(), ShouldBeNil)
+
+			Convey("Entries should be grouped into streams and sorted by timestamp", func() {
+				So(header.Get("Content-Encoding"), ShouldEqual, "snappy")
+				So(header.Get("X-Scope-OrgID"), ShouldEqual, "tenant-a")
+				So(streams, ShouldHaveLength, 2)
+				So(streams[0].labels, ShouldEqual, `{app="api", env="test"}`)
+				So(streams[0].lines, ShouldResemble, []string{"event 1", "event 3"})
+				So(streams[1].labels, ShouldEqual, `{app="worker", env="test"}`)
+				So(streams[1].lines, ShouldResemble, []string{"event 0", "event 2"})
+				So(streams[1].nanos[1], ShouldEqual, base.Add(2*time.Millisecond).UnixNano())
+				So(lokiContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When Loki rejects out of order entries of a stream", func() {
+			status = http.StatusBadRequest
+			response = fmt.Sprintf("entry with timestamp %s ignored, reason: 'entry out of order',\nuser 'tenant-a', total ignored: 1 out of 2 for stream: {app=\"worker\", env=\"test\"}",
+				base.Add(2*time.Millisecond).String())
+			err := lokiContainer.Flush()
+
+			Convey("The rejected entries should be reported per stream", func() {
+				var partialErr *container.PartialFlushError[objectEvent]
+				So(errors.As(err, &partialErr), ShouldBeTrue)
+				So(partialErr.Total, ShouldEqual, 4)
+				So(partialErr.Failed, ShouldHaveLength, 1)
+				So(partialErr.Failed[0].Element.ID, ShouldEqual, 2)
+
+				var streamErr *container.LokiStreamError
+				So(errors.As(partialErr.Failed[0].Err, &streamErr), ShouldBeTrue)
+				So(streamErr.Labels, ShouldEqual, `{app="worker", env="test"}`)
+				So(streamErr.Reasons, ShouldResemble, []string{"entry out of order"})
+				So(lokiContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When Loki is unavailable", func() {
+			status = http.StatusServiceUnavailable
+			err := lokiContainer.Flush()
+
+			Convey("The batch should be kept for next Flush", func() {
+				var statusErr *container.HTTPStatusError
+				So(errors.As(err, &statusErr), ShouldBeTrue)
+				So(lokiContainer.Len(), ShouldEqual, 4)
+			})
+		})
+
+		Convey("When Loki is unavailable for a flush of a Buffer", func() {
+			drops := 0
+			flushBuffer, errChan, err := buffer.NewBuffer[objectEvent](context.Background(), lokiContainer, buffer.Config{
+				ID:               "loki-buffer",
+				DisableAutoFlush: true,
+				Registry:         buffer.NewRegistry(),
+				Hooks: buffer.Hooks{
+					OnDrop: func(id string, reason string, count int) { drops += count },
+				},
+			})
+			So(err, ShouldBeNil)
+			defer flushBuffer.Close()
+			status = http.StatusServiceUnavailable
+			So(flushBuffer.Flush(false), ShouldBeNil)
+
+			Convey("The batch should survive the failed flush and be pushed by next flush", func() {
+				So(<-errChan, ShouldNotBeNil)
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 4)
+				So(drops, ShouldEqual, 0)
+
+				status = http.StatusNoContent
+				So(flushBuffer.Flush(false), ShouldBeNil)
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 0)
+				So(flushBuffer.Stats().LastFlushError, ShouldBeNil)
+				So(streams, ShouldHaveLength, 2)
+			})
+		})
+	})
+}
@@ -0,0 +1,238 @@
+package container
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// natsStandIn a tiny in-process NATS server with JetStream-like acks:
+// messages to subject "events.nack" are nacked, messages to "events.slow" are never acked
+type natsStandIn struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	connect  string
+	messages []string // "subject payload" of published messages
+	msgIDs   []string
+}
+
+func newNATSStandIn() *natsStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	standIn := &natsStandIn{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go standIn.serve(conn)
+		}
+	}()
+	return standIn
+}
+
+func (standIn *natsStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprint(conn, "INFO {\"server_id\":\"stand-in\",\"headers\":true,\"jetstream\":true}\r\n")
+	reader := bufio.NewReader(conn)
+	inboxSID := ""
+	streamSeq := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "CONNECT":
+			standIn.mutex.Lock()
+			standIn.connect = strings.TrimSpace(strings.TrimPrefix(line, "CONNECT"))
+			standIn.mutex.Unlock()
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "SUB":
+			inboxSID = fields[len(fields)-1]
+		case "PUB", "HPUB":
+			subject, reply := fields[1], ""
+			headerSize, totalSize := 0, 0
+			if fields[0] == "PUB" {
+				totalSize, _ = strconv.Atoi(fields[len(fields)-1])
+				if len(fields) == 4 {
+					reply = fields[2]
+				}
+			} else {
+				headerSize, _ = strconv.Atoi(fields[len(fields)-2])
+				totalSize, _ = strconv.Atoi(fields[len(fields)-1])
+				if len(fields) == 5 {
+					reply = fields[2]
+				}
+			}
+			data := make([]byte, totalSize+2)
+			io.ReadFull(reader, data)
+			header, payload := string(data[:headerSize]), string(data[headerSize:totalSize])
+
+			standIn.mutex.Lock()
+			standIn.messages = append(standIn.messages, subject+" "+payload)
+			if strings.Contains(header, "Nats-Msg-Id: ") {
+				standIn.msgIDs = append(standIn.msgIDs, strings.TrimSpace(strings.Split(header, "Nats-Msg-Id: ")[1]))
+			}
+			standIn.mutex.Unlock()
+
+			if reply == "" || inboxSID == "" || subject == "events.slow" {
+				continue
+			}
+			ack := ""
+			if subject == "events.nack" {
+				ack = `{"error":{"code":503,"err_code":10077,"description":"maximum messages exceeded"}}`
+			} else {
+				streamSeq++
+				ack = fmt.Sprintf(`{"stream":"EVENTS","seq":%d}`, streamSeq)
+			}
+			fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", reply, inboxSID, len(ack), ack)
+		}
+	}
+}
+
+func (standIn *natsStandIn) published() []string {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+	return append([]string{}, standIn.messages...)
+}
+
+func TestNATSContainer(t *testing.T) {
+	Convey("Given a NATS stand-in", t, func() {
+		standIn := newNATSStandIn()
+		defer standIn.listener.Close()
+		url := "nats://user:pass@" + standIn.listener.Addr().String()
+
+		Convey("When publishing a batch with core NATS", func() {
+			natsContainer, err := container.NewNATSContainer(container.NATSConfig[objectEvent]{
+				ID:              "test-buffer",
+				FlushSize:       2,
+				URL:             url,
+				SubjectTemplate: "events.{buffer_id}",
+			})
+			So(err, ShouldBeNil)
+			defer natsContainer.Close()
+			So(natsContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+			So(natsContainer.Put(objectEvent{ID: 2, Name: "b"}), ShouldBeNil)
+			So(natsContainer.Flush(), ShouldBeNil)
+
+			Convey("Every element should be published with credentials from url", func() {
+				So(standIn.published(), ShouldResemble, []string{
+					`events.test-buffer {"id":1,"name":"a"}`,
+					`events.test-buffer {"id":2,"name":"b"}`,
+				})
+				So(standIn.connect, ShouldContainSubstring, `"user":"user"`)
+				So(standIn.connect, ShouldContainSubstring, `"pass":"pass"`)
+				So(natsContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When publishing a batch with JetStream", func() {
+			natsContainer, err := container.NewNATSContainer(container.NATSConfig[objectEvent]{
+				FlushSize:      3,
+				URL:            url,
+				Subject:        func(event objectEvent) string { return "events." + event.Name },
+				JetStream:      true,
+				MsgID:          func(event objectEvent) string { return "msg-" + strconv.Itoa(event.ID) },
+				AckTimeout:     500 * time.Millisecond,
+				RequeueUnacked: true,
+			})
+			So(err, ShouldBeNil)
+			defer natsContainer.Close()
+
+			Convey("And every message is acked", func() {
+				So(natsContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+				So(natsContainer.Put(objectEvent{ID: 2, Name: "b"}), ShouldBeNil)
+
+				Convey("The flush should succeed with message ids", func() {
+					So(natsContainer.Flush(), ShouldBeNil)
+					So(standIn.published(), ShouldHaveLength, 2)
+					So(standIn.msgIDs, ShouldResemble, []string{"msg-1", "msg-2"})
+				})
+			})
+
+			Convey("And some messages are nacked or not acked", func() {
+				So(natsContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+				So(natsContainer.Put(objectEvent{ID: 2, Name: "nack"}), ShouldBeNil)
+				So(natsContainer.Put(objectEvent{ID: 3, Name: "slow"}), ShouldBeNil)
+				err := natsContainer.Flush()
+
+				Convey("The un-acked elements should be reported and requeued", func() {
+					var partialErr *container.PartialFlushError[objectEvent]
+					So(errors.As(err, &partialErr), ShouldBeTrue)
+					So(partialErr.Failed, ShouldHaveLength, 2)
+					So(partialErr.Failed[0].Element.ID, ShouldEqual, 2)
+					So(partialErr.Failed[1].Element.ID, ShouldEqual, 3)
+
+					var ackErr *container.NATSAckError
+					So(errors.As(partialErr.Failed[0].Err, &ackErr), ShouldBeTrue)
+					So(ackErr.Code, ShouldEqual, 503)
+					So(natsContainer.Len(), ShouldEqual, 2)
+				})
+			})
+
+			Convey("And the container is closed while a flush is waiting for acks", func() {
+				So(natsContainer.Put(objectEvent{ID: 1, Name: "slow"}), ShouldBeNil)
+				flushed := make(chan error)
+				go func() { flushed <- natsContainer.Flush() }()
+				time.Sleep(100 * time.Millisecond)
+				So(natsContainer.Close(), ShouldBeNil)
+
+				Convey("Close should wait for the flush, which reports the un-acked message", func() {
+					var partialErr *container.PartialFlushError[objectEvent]
+					So(errors.As(<-flushed, &partialErr), ShouldBeTrue)
+					var ackErr *container.NATSAckError
+					So(errors.As(partialErr.Failed[0].Err, &ackErr), ShouldBeTrue)
+					So(ackErr.Code, ShouldEqual, 0)
+				})
+			})
+
+			Convey("And the container runs inside a Buffer", func() {
+				flushBuffer, errChan, err := buffer.NewBuffer[objectEvent](context.Background(), natsContainer, buffer.Config{
+					ID:               "nats-buffer",
+					DisableAutoFlush: true,
+					Registry:         buffer.NewRegistry(),
+				})
+				So(err, ShouldBeNil)
+				// the final flush and Close of container are done by Shutdown, before the deferred Close of container
+				defer flushBuffer.Shutdown(context.Background())
+
+				So(flushBuffer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+				So(flushBuffer.Put(objectEvent{ID: 2, Name: "nack"}), ShouldBeNil)
+				So(flushBuffer.Flush(false), ShouldBeNil)
+				So(<-errChan, ShouldNotBeNil)
+
+				Convey("The un-acked element should survive the failed flush and be published again by next flush", func() {
+					So(flushBuffer.Stats().ContainerLen, ShouldEqual, 1)
+					So(flushBuffer.Flush(false), ShouldBeNil)
+					So(<-errChan, ShouldNotBeNil)
+					So(standIn.published(), ShouldResemble, []string{
+						`events.a {"id":1,"name":"a"}`,
+						`events.nack {"id":2,"name":"nack"}`,
+						`events.nack {"id":2,"name":"nack"}`,
+					})
+				})
+			})
+		})
+	})
+}
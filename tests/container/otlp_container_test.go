@@ -0,0 +1,223 @@
+package container
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoField a decoded protobuf field, embedded messages are kept as Bytes and can be parsed again
+type protoField struct {
+	Num   protowire.Number
+	Value uint64 // value of varint, fixed64 and fixed32 field
+	Bytes []byte // value of bytes field(string, bytes and embedded message)
+}
+
+// parseProto decode every field of a message
+func parseProto(data []byte) (fields []protoField, err error) {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		field := protoField{Num: num}
+		switch typ {
+		case protowire.VarintType:
+			field.Value, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed64Type:
+			field.Value, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			field.Bytes, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		fields = append(fields, field)
+	}
+	return
+}
+
+// otlpField return the embedded fields of the first field numbered num
+func otlpField(fields []protoField, num protowire.Number) []protoField {
+	for _, field := range fields {
+		if field.Num == num {
+			embedded, err := parseProto(field.Bytes)
+			if err != nil {
+				panic(err)
+			}
+			return embedded
+		}
+	}
+	return nil
+}
+
+// otlpFields return every field numbered num
+func otlpFields(fields []protoField, num protowire.Number) (matched []protoField) {
+	for _, field := range fields {
+		if field.Num == num {
+			matched = append(matched, field)
+		}
+	}
+	return
+}
+
+func TestOTLPContainer(t *testing.T) {
+	Convey("Given an OTLP/HTTP collector stand-in", t, func() {
+		var (
+			path, contentType string
+			body              []byte
+			partialSuccess    []byte
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+			reader, err := gzip.NewReader(r.Body)
+			if err == nil {
+				body, _ = io.ReadAll(reader)
+			}
+			w.Header().Set("Content-Type", "application/x-protobuf")
+			if partialSuccess != nil {
+				var encoded, response []byte
+				encoded = protowire.AppendTag(encoded, 1, protowire.VarintType)
+				encoded = protowire.AppendVarint(encoded, 1)
+				encoded = protowire.AppendTag(encoded, 2, protowire.BytesType)
+				encoded = protowire.AppendBytes(encoded, partialSuccess)
+				response = protowire.AppendTag(response, 1, protowire.BytesType)
+				w.Write(protowire.AppendBytes(response, encoded))
+			}
+		}))
+		defer server.Close()
+
+		Convey("When exporting a batch of log records", func() {
+			otlpContainer, err := container.NewOTLPContainer(container.OTLPConfig[objectEvent]{
+				FlushSize: 2,
+				Endpoint:  server.URL,
+				Resource:  map[string]any{"service.name": "test"},
+				LogRecord: func(event objectEvent) container.OTLPLogRecord {
+					return container.OTLPLogRecord{
+						Timestamp:      time.Unix(1700000000, 0),
+						SeverityNumber: 9,
+						SeverityText:   "INFO",
+						Body:           event.Name,
+						Attributes:     map[string]any{"id": event.ID, "ok": false},
+					}
+				},
+			})
+			So(err, ShouldBeNil)
+			So(otlpContainer.Put(objectEvent{ID: 0, Name: "a"}), ShouldBeNil)
+			So(otlpContainer.Put(objectEvent{ID: 2, Name: "b"}), ShouldBeNil)
+			So(otlpContainer.Flush(), ShouldBeNil)
+
+			Convey("The batch should be posted as ExportLogsServiceRequest", func() {
+				So(path, ShouldEqual, "/v1/logs")
+				So(contentType, ShouldEqual, "application/x-protobuf")
+
+				request, err := parseProto(body)
+				So(err, ShouldBeNil)
+				resourceLogs := otlpField(request, 1)
+				resourceAttribute := otlpField(otlpField(resourceLogs, 1), 1)
+				So(string(otlpFields(resourceAttribute, 1)[0].Bytes), ShouldEqual, "service.name")
+				scopeLogs := otlpField(resourceLogs, 2)
+				So(string(otlpFields(otlpField(scopeLogs, 1), 1)[0].Bytes), ShouldEqual, "github.com/Kevinello/go-buffer")
+				records := otlpFields(scopeLogs, 2)
+				So(records, ShouldHaveLength, 2)
+
+				record, _ := parseProto(records[0].Bytes)
+				So(otlpFields(record, 1)[0].Value, ShouldEqual, uint64(time.Unix(1700000000, 0).UnixNano()))
+				So(otlpFields(record, 2)[0].Value, ShouldEqual, 9)
+				So(string(otlpFields(otlpField(record, 5), 1)[0].Bytes), ShouldEqual, "a")
+				So(otlpFields(record, 11), ShouldHaveLength, 1)
+
+				// zero values inside AnyValue should still be present
+				attributes := otlpFields(record, 6)
+				So(attributes, ShouldHaveLength, 2)
+				id, _ := parseProto(attributes[0].Bytes)
+				So(string(otlpFields(id, 1)[0].Bytes), ShouldEqual, "id")
+				So(otlpFields(otlpField(id, 2), 3), ShouldHaveLength, 1)
+				ok, _ := parseProto(attributes[1].Bytes)
+				So(otlpFields(otlpField(ok, 2), 2), ShouldHaveLength, 1)
+				So(otlpContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the collector reports partial success for spans", func() {
+			partialSuccess = []byte("span name is empty")
+			otlpContainer, err := container.NewOTLPContainer(container.OTLPConfig[objectEvent]{
+				FlushSize:   1,
+				Endpoint:    server.URL + "/",
+				DisableGzip: true,
+				Span: func(event objectEvent) container.OTLPSpan {
+					return container.OTLPSpan{TraceID: [16]byte{1}, SpanID: [8]byte{byte(event.ID)}}
+				},
+			})
+			So(err, ShouldBeNil)
+			So(otlpContainer.Put(objectEvent{ID: 1}), ShouldBeNil)
+			err = otlpContainer.Flush()
+
+			Convey("Flush should return OTLPPartialSuccessError", func() {
+				So(path, ShouldEqual, "/v1/traces")
+				var partialErr *container.OTLPPartialSuccessError
+				So(errors.As(err, &partialErr), ShouldBeTrue)
+				So(partialErr.Rejected, ShouldEqual, 1)
+				So(partialErr.Message, ShouldEqual, "span name is empty")
+
+				var keptErr *container.KeptError
+				So(errors.As(err, &keptErr), ShouldBeTrue)
+				So(keptErr.Dropped, ShouldEqual, 1)
+				So(otlpContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the collector rejects part of a batch exported by a Buffer", func() {
+			partialSuccess = []byte("span name is empty")
+			otlpContainer, err := container.NewOTLPContainer(container.OTLPConfig[objectEvent]{
+				FlushSize: 3,
+				Endpoint:  server.URL,
+				Span: func(event objectEvent) container.OTLPSpan {
+					return container.OTLPSpan{TraceID: [16]byte{1}, SpanID: [8]byte{byte(event.ID)}}
+				},
+			})
+			So(err, ShouldBeNil)
+			dropped := 0
+			flushBuffer, errChan, err := buffer.NewBuffer[objectEvent](context.Background(), otlpContainer, buffer.Config{
+				ID:               "otlp-buffer",
+				DisableAutoFlush: true,
+				Registry:         buffer.NewRegistry(),
+				Hooks:            buffer.Hooks{OnDrop: func(id string, reason string, count int) { dropped += count }},
+			})
+			So(err, ShouldBeNil)
+			defer flushBuffer.Close()
+			for i := 0; i < 3; i++ {
+				So(flushBuffer.Put(objectEvent{ID: i, Name: "span"}), ShouldBeNil)
+			}
+			So(flushBuffer.Flush(false), ShouldBeNil)
+
+			Convey("Only the rejected records should be counted as dropped", func() {
+				So(<-errChan, ShouldNotBeNil)
+				So(dropped, ShouldEqual, 1)
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When neither LogRecord nor Span is set", func() {
+			_, err := container.NewOTLPContainer(container.OTLPConfig[objectEvent]{Endpoint: server.URL})
+
+			Convey("NewOTLPContainer should fail", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
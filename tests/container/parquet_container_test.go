@@ -0,0 +1,544 @@
+package container
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer/container"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type parquetEvent struct {
+	ID      int64     `parquet:"id"`
+	Name    string    `parquet:"name"`
+	Score   *float64  `parquet:"score"`
+	Created time.Time `parquet:"created,timestamp=micros"`
+	Ignored string    `parquet:"-"`
+}
+
+type memoryFile struct {
+	bytes.Buffer
+	closed   bool
+	failures int // the next failures writes would fail
+}
+
+func (file *memoryFile) Write(data []byte) (int, error) {
+	if file.failures > 0 {
+		file.failures--
+		return 0, errors.New("disk is full")
+	}
+	return file.Buffer.Write(data)
+}
+
+func (file *memoryFile) Close() error {
+	file.closed = true
+	return nil
+}
+
+// thriftReader read thrift compact protocol, structs are decoded into maps of field id,
+// integers into int64, binaries into []byte and lists into []any
+type thriftReader struct {
+	data   []byte
+	offset int
+}
+
+func (reader *thriftReader) byte() byte {
+	b := reader.data[reader.offset]
+	reader.offset++
+	return b
+}
+
+func (reader *thriftReader) uvarint() uint64 {
+	value, n := binary.Uvarint(reader.data[reader.offset:])
+	if n <= 0 {
+		panic("invalid varint")
+	}
+	reader.offset += n
+	return value
+}
+
+func (reader *thriftReader) varint() int64 {
+	value := reader.uvarint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+func (reader *thriftReader) value(typ byte) any {
+	switch typ {
+	case 1, 2: // boolean of field header
+		return typ == 1
+	case 3:
+		return int64(int8(reader.byte()))
+	case 4, 5, 6:
+		return reader.varint()
+	case 7:
+		value := math.Float64frombits(binary.LittleEndian.Uint64(reader.data[reader.offset:]))
+		reader.offset += 8
+		return value
+	case 8:
+		size := int(reader.uvarint())
+		value := reader.data[reader.offset : reader.offset+size]
+		reader.offset += size
+		return value
+	case 9, 10:
+		header := reader.byte()
+		size, elemType := int(header>>4), header&0x0f
+		if size == 15 {
+			size = int(reader.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			if elemType == 1 || elemType == 2 {
+				list[i] = reader.byte() == 1
+			} else {
+				list[i] = reader.value(elemType)
+			}
+		}
+		return list
+	case 12:
+		return reader.readStruct()
+	}
+	panic(fmt.Sprintf("unsupported thrift type %d", typ))
+}
+
+func (reader *thriftReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var id int16
+	for {
+		header := reader.byte()
+		if header == 0 {
+			return fields
+		}
+		if delta := header >> 4; delta != 0 {
+			id += int16(delta)
+		} else {
+			id = int16(reader.varint())
+		}
+		fields[id] = reader.value(header & 0x0f)
+	}
+}
+
+// parquetSchemaElement a leaf column of the decoded schema
+type parquetSchemaElement struct {
+	name          string
+	physicalType  int64
+	optional      bool
+	convertedType int64 // -1 if absent
+}
+
+// parquetFileContent a parquet file decoded without the container, values of every column are concatenated across row groups,
+// nil for null, int32 and int64 for INT32 and INT64, float64 for DOUBLE, string for BYTE_ARRAY and bool for BOOLEAN
+type parquetFileContent struct {
+	numRows   int64
+	schema    []parquetSchemaElement
+	rowGroups []int64 // rows of every row group
+	codecs    []int64 // codecs of every column chunk
+	columns   map[string][]any
+}
+
+func decodeParquetFile(content []byte) (file parquetFileContent, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed parquet file: %v", r)
+		}
+	}()
+	if !bytes.Equal(content[:4], []byte("PAR1")) || !bytes.Equal(content[len(content)-4:], []byte("PAR1")) {
+		return file, errors.New("missing magic")
+	}
+	footerLength := int(binary.LittleEndian.Uint32(content[len(content)-8:]))
+	footer := (&thriftReader{data: content[len(content)-8-footerLength : len(content)-8]}).readStruct()
+
+	file.numRows = footer[3].(int64)
+	schema := footer[2].([]any)
+	if root := schema[0].(map[int16]any); root[5].(int64) != int64(len(schema)-1) {
+		return file, errors.New("num_children of root mismatches")
+	}
+	for _, element := range schema[1:] {
+		fields := element.(map[int16]any)
+		leaf := parquetSchemaElement{
+			name:          string(fields[4].([]byte)),
+			physicalType:  fields[1].(int64),
+			optional:      fields[3].(int64) == 1,
+			convertedType: -1,
+		}
+		if converted, ok := fields[6]; ok {
+			leaf.convertedType = converted.(int64)
+		}
+		file.schema = append(file.schema, leaf)
+	}
+
+	file.columns = make(map[string][]any)
+	for _, rowGroup := range footer[4].([]any) {
+		rowGroupFields := rowGroup.(map[int16]any)
+		rows := rowGroupFields[3].(int64)
+		file.rowGroups = append(file.rowGroups, rows)
+		for i, chunk := range rowGroupFields[1].([]any) {
+			meta := chunk.(map[int16]any)[3].(map[int16]any)
+			leaf := file.schema[i]
+			if name := string(meta[3].([]any)[0].([]byte)); name != leaf.name {
+				return file, fmt.Errorf("path %s of column chunk mismatches column %s", name, leaf.name)
+			}
+			if meta[5].(int64) != rows {
+				return file, fmt.Errorf("num_values of column %s mismatches rows of row group", leaf.name)
+			}
+			codec := meta[4].(int64)
+			file.codecs = append(file.codecs, codec)
+
+			reader := &thriftReader{data: content, offset: int(meta[9].(int64))}
+			header := reader.readStruct()
+			compressed := content[reader.offset : reader.offset+int(header[3].(int64))]
+			page, err := decompressParquetPage(codec, compressed)
+			if err != nil {
+				return file, err
+			}
+			if len(page) != int(header[2].(int64)) {
+				return file, fmt.Errorf("uncompressed size of page of column %s mismatches", leaf.name)
+			}
+			values, err := decodeParquetPage(leaf, page, int(header[5].(map[int16]any)[1].(int64)))
+			if err != nil {
+				return file, err
+			}
+			file.columns[leaf.name] = append(file.columns[leaf.name], values...)
+		}
+	}
+	return
+}
+
+func decompressParquetPage(codec int64, compressed []byte) ([]byte, error) {
+	switch codec {
+	case 0:
+		return compressed, nil
+	case 1:
+		return snappy.Decode(nil, compressed)
+	case 2:
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(reader)
+	case 6:
+		decoder, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		return decoder.DecodeAll(compressed, nil)
+	}
+	return nil, fmt.Errorf("unknown codec %d", codec)
+}
+
+// decodeParquetPage decode a data page with definition levels(max level 1, RLE/bit-packing hybrid) and PLAIN values
+func decodeParquetPage(leaf parquetSchemaElement, page []byte, numValues int) ([]any, error) {
+	defined := make([]bool, 0, numValues)
+	if leaf.optional {
+		length := int(binary.LittleEndian.Uint32(page))
+		levels := page[4 : 4+length]
+		page = page[4+length:]
+		for len(levels) > 0 && len(defined) < numValues {
+			header, n := binary.Uvarint(levels)
+			levels = levels[n:]
+			if header&1 == 0 {
+				for i := uint64(0); i < header>>1; i++ {
+					defined = append(defined, levels[0] == 1)
+				}
+				levels = levels[1:]
+			} else {
+				for i := 0; i < int(header>>1)*8; i++ {
+					defined = append(defined, levels[i/8]>>(i%8)&1 == 1)
+				}
+				levels = levels[header>>1:]
+			}
+		}
+		if len(defined) < numValues {
+			return nil, fmt.Errorf("missing definition levels of column %s", leaf.name)
+		}
+		defined = defined[:numValues]
+	} else {
+		for i := 0; i < numValues; i++ {
+			defined = append(defined, true)
+		}
+	}
+
+	values := make([]any, 0, numValues)
+	bit := 0
+	for _, isDefined := range defined {
+		if !isDefined {
+			values = append(values, nil)
+			continue
+		}
+		switch leaf.physicalType {
+		case 0:
+			values = append(values, page[bit/8]>>(bit%8)&1 == 1)
+			bit++
+		case 1:
+			values = append(values, int32(binary.LittleEndian.Uint32(page)))
+			page = page[4:]
+		case 2:
+			values = append(values, int64(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case 5:
+			values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case 6:
+			size := int(binary.LittleEndian.Uint32(page))
+			values = append(values, string(page[4:4+size]))
+			page = page[4+size:]
+		default:
+			return nil, fmt.Errorf("unsupported physical type %d", leaf.physicalType)
+		}
+	}
+	return values, nil
+}
+
+// parquetRow cover every kind of column: required and optional, converted types and bit-packed booleans
+type parquetRow struct {
+	ID      int64     `parquet:"id"`
+	Name    string    `parquet:"name"`
+	Score   *float64  `parquet:"score"`
+	Created time.Time `parquet:"created,timestamp=micros"`
+	Active  bool      `parquet:"active"`
+	Level   int8      `parquet:"level"`
+	Count   *uint32   `parquet:"count"`
+	Payload []byte    `parquet:"payload"`
+	Ignored string    `parquet:"-"`
+}
+
+func TestParquetContainer(t *testing.T) {
+	Convey("Given a ParquetContainer rolling files every 2 flushes", t, func() {
+		files := make([]*memoryFile, 0)
+		parquetContainer, err := container.NewParquetContainer[parquetEvent](container.ParquetConfig{
+			FlushSize:        10,
+			RowGroupSize:     4,
+			RowGroupsPerFile: 2,
+			Codec:            container.ParquetGzip,
+			CreateFile: func(seq int) (io.WriteCloser, error) {
+				file := &memoryFile{}
+				files = append(files, file)
+				return file, nil
+			},
+		})
+		So(err, ShouldBeNil)
+
+		put := func(n int) {
+			for i := 0; i < n; i++ {
+				score := float64(i)
+				event := parquetEvent{ID: int64(i), Name: "event", Created: time.Now()}
+				if i%2 == 0 {
+					event.Score = &score
+				}
+				So(parquetContainer.Put(event), ShouldBeNil)
+			}
+		}
+
+		Convey("When putting rows until it is full", func() {
+			put(10)
+
+			Convey("The container should be full", func() {
+				So(parquetContainer.IsFull(), ShouldBeTrue)
+				So(parquetContainer.Len(), ShouldEqual, 10)
+				So(parquetContainer.Bytes(), ShouldEqual, 0)
+			})
+
+			Convey("And the first flush of 3 row groups should finish a file and open the next one", func() {
+				So(parquetContainer.Flush(), ShouldBeNil)
+				So(parquetContainer.Len(), ShouldEqual, 0)
+				So(files, ShouldHaveLength, 2)
+				So(files[0].closed, ShouldBeTrue)
+				So(files[1].closed, ShouldBeFalse)
+				So(files[1].Bytes()[:4], ShouldResemble, []byte("PAR1"))
+				// bytes of the unfinished file are held by container
+				So(parquetContainer.Bytes(), ShouldEqual, files[1].Len())
+
+				content, err := decodeParquetFile(files[0].Bytes())
+				So(err, ShouldBeNil)
+				So(content.numRows, ShouldEqual, 8)
+				So(content.rowGroups, ShouldResemble, []int64{4, 4})
+				So(content.columns["id"], ShouldResemble, []any{int64(0), int64(1), int64(2), int64(3), int64(4), int64(5), int64(6), int64(7)})
+
+				Convey("And the second flush should finish the second file", func() {
+					put(3)
+					So(parquetContainer.Flush(), ShouldBeNil)
+					So(files, ShouldHaveLength, 2)
+					So(files[1].closed, ShouldBeTrue)
+					So(parquetContainer.Bytes(), ShouldEqual, 0)
+
+					Convey("And the next flush should roll to a new file", func() {
+						put(1)
+						So(parquetContainer.Flush(), ShouldBeNil)
+						So(files, ShouldHaveLength, 3)
+					})
+				})
+			})
+		})
+
+		Convey("When closing the container with an opening file", func() {
+			put(5)
+			So(parquetContainer.Flush(), ShouldBeNil)
+			So(parquetContainer.Close(), ShouldBeNil)
+
+			Convey("The file should be finished", func() {
+				So(files, ShouldHaveLength, 1)
+				So(files[0].closed, ShouldBeTrue)
+				content, err := decodeParquetFile(files[0].Bytes())
+				So(err, ShouldBeNil)
+				So(content.numRows, ShouldEqual, 5)
+				So(content.columns["score"], ShouldResemble, []any{0.0, nil, 2.0, nil, 4.0})
+			})
+		})
+
+		Convey("When failing to write a row group into an opening file", func() {
+			put(3)
+			So(parquetContainer.Flush(), ShouldBeNil)
+			written := files[0].Len()
+			files[0].failures = 1
+			put(6)
+			err := parquetContainer.Flush()
+
+			Convey("The file should be finished with the row group written before, and the rows should be kept", func() {
+				var keptErr *container.KeptError
+				So(errors.As(err, &keptErr), ShouldBeTrue)
+				So(keptErr.Dropped, ShouldEqual, 0)
+				So(parquetContainer.Len(), ShouldEqual, 6)
+				So(files[0].closed, ShouldBeTrue)
+				So(files[0].Len(), ShouldBeGreaterThan, written)
+				content, err := decodeParquetFile(files[0].Bytes())
+				So(err, ShouldBeNil)
+				So(content.numRows, ShouldEqual, 3)
+				So(content.rowGroups, ShouldResemble, []int64{3})
+
+				Convey("And the next flush should write the kept rows into a new file", func() {
+					So(parquetContainer.Flush(), ShouldBeNil)
+					So(parquetContainer.Len(), ShouldEqual, 0)
+					So(files, ShouldHaveLength, 2)
+				})
+			})
+		})
+
+		Convey("When flushing an empty container", func() {
+			So(parquetContainer.Flush(), ShouldBeNil)
+
+			Convey("No file should be created", func() {
+				So(files, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given rows written by ParquetContainer with every codec", t, func() {
+		base := time.UnixMicro(1700000000123456).UTC()
+		rows := make([]parquetRow, 5)
+		for i := range rows {
+			rows[i] = parquetRow{
+				ID:      int64(i),
+				Name:    fmt.Sprintf("row %d", i),
+				Created: base.Add(time.Duration(i) * time.Microsecond),
+				Active:  i%2 == 1,
+				Level:   int8(-i),
+				Payload: []byte{byte(i), 0xff},
+				Ignored: "ignored",
+			}
+			if i%2 == 0 {
+				score := float64(i) * 1.5
+				rows[i].Score = &score
+			}
+			if i%3 != 0 {
+				count := uint32(i * 10)
+				rows[i].Count = &count
+			}
+		}
+
+		for _, codec := range []container.ParquetCodec{container.ParquetUncompressed, container.ParquetSnappy, container.ParquetGzip, container.ParquetZstd} {
+			codec := codec
+			Convey(fmt.Sprintf("The file compressed by codec %d should be decoded into the rows", codec), func() {
+				file := &memoryFile{}
+				parquetContainer, err := container.NewParquetContainer[parquetRow](container.ParquetConfig{
+					FlushSize:        len(rows),
+					RowGroupSize:     2,
+					RowGroupsPerFile: 3,
+					Codec:            codec,
+					CreateFile:       func(seq int) (io.WriteCloser, error) { return file, nil },
+				})
+				So(err, ShouldBeNil)
+				for _, row := range rows {
+					So(parquetContainer.Put(row), ShouldBeNil)
+				}
+				So(parquetContainer.Flush(), ShouldBeNil)
+				So(file.closed, ShouldBeTrue)
+
+				content, err := decodeParquetFile(file.Bytes())
+				So(err, ShouldBeNil)
+				So(content.numRows, ShouldEqual, len(rows))
+				So(content.rowGroups, ShouldResemble, []int64{2, 2, 1})
+				for _, chunkCodec := range content.codecs {
+					So(chunkCodec, ShouldEqual, codec)
+				}
+				So(content.schema, ShouldResemble, []parquetSchemaElement{
+					{name: "id", physicalType: 2, convertedType: -1},
+					{name: "name", physicalType: 6, convertedType: 0},
+					{name: "score", physicalType: 5, optional: true, convertedType: -1},
+					{name: "created", physicalType: 2, convertedType: 10},
+					{name: "active", physicalType: 0, convertedType: -1},
+					{name: "level", physicalType: 1, convertedType: 15},
+					{name: "count", physicalType: 1, optional: true, convertedType: 13},
+					{name: "payload", physicalType: 6, convertedType: -1},
+				})
+
+				So(content.columns["id"], ShouldResemble, []any{int64(0), int64(1), int64(2), int64(3), int64(4)})
+				So(content.columns["name"], ShouldResemble, []any{"row 0", "row 1", "row 2", "row 3", "row 4"})
+				So(content.columns["score"], ShouldResemble, []any{0.0, nil, 3.0, nil, 6.0})
+				So(content.columns["created"], ShouldResemble, []any{
+					base.UnixMicro(), base.UnixMicro() + 1, base.UnixMicro() + 2, base.UnixMicro() + 3, base.UnixMicro() + 4,
+				})
+				So(content.columns["active"], ShouldResemble, []any{false, true, false, true, false})
+				So(content.columns["level"], ShouldResemble, []any{int32(0), int32(-1), int32(-2), int32(-3), int32(-4)})
+				So(content.columns["count"], ShouldResemble, []any{nil, int32(10), int32(20), nil, int32(40)})
+				So(content.columns["payload"], ShouldResemble, []any{"\x00\xff", "\x01\xff", "\x02\xff", "\x03\xff", "\x04\xff"})
+			})
+		}
+	})
+
+	Convey("Given a ParquetContainer failing to create file once", t, func() {
+		failures := 1
+		parquetContainer, err := container.NewParquetContainer[parquetEvent](container.ParquetConfig{
+			FlushSize: 10,
+			CreateFile: func(seq int) (io.WriteCloser, error) {
+				if failures > 0 {
+					failures--
+					return nil, errors.New("permission denied")
+				}
+				return &memoryFile{}, nil
+			},
+		})
+		So(err, ShouldBeNil)
+		So(parquetContainer.Put(parquetEvent{ID: 1}), ShouldBeNil)
+
+		Convey("The rows should be kept for next flush", func() {
+			var keptErr *container.KeptError
+			So(errors.As(parquetContainer.Flush(), &keptErr), ShouldBeTrue)
+			So(parquetContainer.Len(), ShouldEqual, 1)
+			So(parquetContainer.Flush(), ShouldBeNil)
+			So(parquetContainer.Len(), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Given a row type with unsupported field", t, func() {
+		type badRow struct {
+			Tags map[string]string `parquet:"tags"`
+		}
+		_, err := container.NewParquetContainer[badRow](container.ParquetConfig{
+			FlushSize:  10,
+			CreateFile: func(seq int) (io.WriteCloser, error) { return &memoryFile{}, nil },
+		})
+
+		Convey("NewParquetContainer should return error", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
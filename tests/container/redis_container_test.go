@@ -0,0 +1,234 @@
+package container
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// redisStandIn a tiny in-process redis speaking RESP, it records every command,
+// reply WRONGTYPE for key "bad" and close the connection for key "drop"
+type redisStandIn struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	commands [][]string
+}
+
+func newRedisStandIn() *redisStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	standIn := &redisStandIn{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go standIn.serve(conn)
+		}
+	}()
+	return standIn
+}
+
+func (standIn *redisStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		command := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			line, _ = reader.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			arg := make([]byte, size+2)
+			io.ReadFull(reader, arg)
+			command = append(command, string(arg[:size]))
+		}
+		standIn.mutex.Lock()
+		standIn.commands = append(standIn.commands, command)
+		standIn.mutex.Unlock()
+
+		switch {
+		case len(command) > 1 && command[1] == "drop":
+			return
+		case len(command) > 1 && command[1] == "bad":
+			fmt.Fprint(conn, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n")
+		case command[0] == "XADD":
+			fmt.Fprint(conn, "$15\r\n1700000000000-0\r\n")
+		case command[0] == "RPUSH" || command[0] == "ZADD":
+			fmt.Fprint(conn, ":1\r\n")
+		default:
+			fmt.Fprint(conn, "+OK\r\n")
+		}
+	}
+}
+
+func (standIn *redisStandIn) received() [][]string {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+	return append([][]string{}, standIn.commands...)
+}
+
+func TestRedisContainer(t *testing.T) {
+	Convey("Given a redis stand-in", t, func() {
+		standIn := newRedisStandIn()
+		defer standIn.listener.Close()
+
+		Convey("When flushing a batch with XADD", func() {
+			redisContainer, err := container.NewRedisContainer(container.RedisConfig[objectEvent]{
+				ID:          "test-buffer",
+				FlushSize:   2,
+				Addr:        standIn.listener.Addr().String(),
+				Password:    "secret",
+				DB:          2,
+				KeyTemplate: "stream:{buffer_id}",
+				Fields: func(event objectEvent) (map[string]string, error) {
+					return map[string]string{"name": event.Name, "id": strconv.Itoa(event.ID)}, nil
+				},
+				MaxLen: 1000,
+			})
+			So(err, ShouldBeNil)
+			defer redisContainer.Close()
+			So(redisContainer.Put(objectEvent{ID: 1, Name: "a"}), ShouldBeNil)
+			So(redisContainer.Put(objectEvent{ID: 2, Name: "b"}), ShouldBeNil)
+			So(redisContainer.Flush(), ShouldBeNil)
+
+			Convey("The connection should be authenticated and every element should be added with trimming", func() {
+				commands := standIn.received()
+				So(commands, ShouldHaveLength, 4)
+				So(commands[0], ShouldResemble, []string{"AUTH", "secret"})
+				So(commands[1], ShouldResemble, []string{"SELECT", "2"})
+				So(commands[2], ShouldResemble, []string{"XADD", "stream:test-buffer", "MAXLEN", "~", "1000", "*", "id", "1", "name", "a"})
+				So(commands[3], ShouldResemble, []string{"XADD", "stream:test-buffer", "MAXLEN", "~", "1000", "*", "id", "2", "name", "b"})
+				So(redisContainer.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When some ZADD commands fail", func() {
+			redisContainer, err := container.NewRedisContainer(container.RedisConfig[objectEvent]{
+				FlushSize: 3,
+				Addr:      standIn.listener.Addr().String(),
+				Command:   container.RedisZAdd,
+				Key:       func(event objectEvent) string { return event.Name },
+				Member:    func(event objectEvent) (string, error) { return strconv.Itoa(event.ID), nil },
+				Score:     func(event objectEvent) float64 { return float64(event.ID) * 1.5 },
+			})
+			So(err, ShouldBeNil)
+			defer redisContainer.Close()
+			So(redisContainer.Put(objectEvent{ID: 1, Name: "ranking"}), ShouldBeNil)
+			So(redisContainer.Put(objectEvent{ID: 2, Name: "bad"}), ShouldBeNil)
+			So(redisContainer.Put(objectEvent{ID: 3, Name: "ranking"}), ShouldBeNil)
+			err = redisContainer.Flush()
+
+			Convey("Flush should report the failed commands", func() {
+				var partialErr *container.PartialFlushError[objectEvent]
+				So(errors.As(err, &partialErr), ShouldBeTrue)
+				So(partialErr.Failed, ShouldHaveLength, 1)
+				So(partialErr.Failed[0].Element.ID, ShouldEqual, 2)
+
+				var redisErr *container.RedisError
+				So(errors.As(err, &redisErr), ShouldBeTrue)
+				So(redisErr.Command, ShouldEqual, "ZADD bad")
+				So(redisErr.Message, ShouldStartWith, "WRONGTYPE")
+				So(standIn.received()[0], ShouldResemble, []string{"ZADD", "ranking", "1.5", "1"})
+			})
+		})
+
+		Convey("When the connection is broken during RPUSH", func() {
+			redisContainer, err := container.NewRedisContainer(container.RedisConfig[objectEvent]{
+				FlushSize: 2,
+				Addr:      standIn.listener.Addr().String(),
+				Command:   container.RedisRPush,
+				Key:       func(event objectEvent) string { return event.Name },
+			})
+			So(err, ShouldBeNil)
+			defer redisContainer.Close()
+			So(redisContainer.Put(objectEvent{ID: 1, Name: "drop"}), ShouldBeNil)
+			err = redisContainer.Flush()
+
+			Convey("The batch should be kept and sent again after reconnecting", func() {
+				So(err, ShouldNotBeNil)
+				So(redisContainer.Len(), ShouldEqual, 1)
+
+				redisContainer.Reset()
+				So(redisContainer.Put(objectEvent{ID: 2, Name: "list"}), ShouldBeNil)
+				So(redisContainer.Flush(), ShouldBeNil)
+				commands := standIn.received()
+				So(commands[len(commands)-1], ShouldResemble, []string{"RPUSH", "list", `{"id":2,"name":"list"}`})
+			})
+		})
+
+		Convey("When the connection is broken in the middle of a pipeline", func() {
+			redisContainer, err := container.NewRedisContainer(container.RedisConfig[objectEvent]{
+				FlushSize: 4,
+				Addr:      standIn.listener.Addr().String(),
+				Command:   container.RedisRPush,
+				Key:       func(event objectEvent) string { return event.Name },
+			})
+			So(err, ShouldBeNil)
+			defer redisContainer.Close()
+			So(redisContainer.Put(objectEvent{ID: 1, Name: "list"}), ShouldBeNil)
+			So(redisContainer.Put(objectEvent{ID: 2, Name: "bad"}), ShouldBeNil)
+			So(redisContainer.Put(objectEvent{ID: 3, Name: "drop"}), ShouldBeNil)
+			So(redisContainer.Put(objectEvent{ID: 4, Name: "list"}), ShouldBeNil)
+			err = redisContainer.Flush()
+
+			Convey("Only the commands without reply should be kept", func() {
+				var partialErr *container.PartialFlushError[objectEvent]
+				So(errors.As(err, &partialErr), ShouldBeTrue)
+				So(partialErr.Failed, ShouldHaveLength, 3)
+				So(partialErr.Failed[0].Element.ID, ShouldEqual, 2)
+				So(partialErr.Failed[0].Requeued, ShouldBeFalse)
+				So(partialErr.Failed[1].Element.ID, ShouldEqual, 3)
+				So(partialErr.Failed[2].Element.ID, ShouldEqual, 4)
+				So(partialErr.Requeued(), ShouldEqual, 2)
+				So(redisContainer.Len(), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the connection is broken during a flush of a Buffer", func() {
+			redisContainer, err := container.NewRedisContainer(container.RedisConfig[objectEvent]{
+				FlushSize: 2,
+				Addr:      standIn.listener.Addr().String(),
+				Command:   container.RedisRPush,
+				Key:       func(event objectEvent) string { return event.Name },
+			})
+			So(err, ShouldBeNil)
+			drops := 0
+			flushBuffer, errChan, err := buffer.NewBuffer[objectEvent](context.Background(), redisContainer, buffer.Config{
+				ID:               "redis-buffer",
+				DisableAutoFlush: true,
+				Registry:         buffer.NewRegistry(),
+				Hooks: buffer.Hooks{
+					OnDrop: func(id string, reason string, count int) { drops += count },
+				},
+			})
+			So(err, ShouldBeNil)
+			defer flushBuffer.Close()
+			So(flushBuffer.Put(objectEvent{ID: 1, Name: "drop"}), ShouldBeNil)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+
+			Convey("The batch should survive the failed flush", func() {
+				So(<-errChan, ShouldNotBeNil)
+				So(flushBuffer.Stats().ContainerLen, ShouldEqual, 1)
+				So(drops, ShouldEqual, 0)
+			})
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBufferErrors(t *testing.T) {
+	Convey("Given a Buffer whose container fails part of every batch", t, func() {
+		insertErr := errors.New("insert failed")
+		arrayContainer := container.NewArrayContainer(10, false, func(array []int) error {
+			if len(array) == 0 {
+				return nil
+			}
+			return &container.PartialFlushError[int]{
+				Total:  len(array),
+				Failed: []container.FailedElement[int]{{Element: array[len(array)-1], Err: insertErr}},
+			}
+		})
+
+		var (
+			mutex   sync.Mutex
+			handled []buffer.FlushError
+		)
+		flushBuffer, errChan, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+			ID:               "error-buffer",
+			DisableAutoFlush: true,
+			Registry:         buffer.NewRegistry(),
+			ErrorHandler: func(err buffer.FlushError) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				handled = append(handled, err)
+			},
+		})
+		So(err, ShouldBeNil)
+		So(errChan, ShouldNotBeNil)
+		defer flushBuffer.Close()
+
+		Convey("A failed flush should be delivered as FlushError with batch context", func() {
+			So(flushBuffer.Put(1), ShouldBeNil)
+			So(flushBuffer.Put(2), ShouldBeNil)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+
+			received := <-errChan
+			var flushErr *buffer.FlushError
+			So(errors.As(received, &flushErr), ShouldBeTrue)
+			So(errors.Is(received, insertErr), ShouldBeTrue)
+			So(flushErr.ID, ShouldEqual, "error-buffer")
+			So(flushErr.Trigger, ShouldEqual, buffer.FlushTriggerManual)
+			So(flushErr.BatchSize, ShouldEqual, 2)
+			So(flushErr.Attempt, ShouldEqual, 1)
+			So(flushErr.Records, ShouldResemble, []int{2})
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			So(handled, ShouldHaveLength, 1)
+			So(handled[0].Records, ShouldResemble, []int{2})
+		})
+
+		Convey("Errors should be dropped instead of blocking when nobody drains the channel", func() {
+			for i := 0; i < 3; i++ {
+				So(flushBuffer.Put(i), ShouldBeNil)
+				So(flushBuffer.Flush(false), ShouldBeNil)
+			}
+			So(flushBuffer.Stats().DroppedErrors, ShouldEqual, 2)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			So(handled, ShouldHaveLength, 3)
+		})
+	})
+}
@@ -0,0 +1,176 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBufferHooks(t *testing.T) {
+	Convey("Given a Buffer with hooks recording events", t, func() {
+		var (
+			mutex  sync.Mutex
+			events []string
+			done   buffer.FlushInfo
+		)
+		record := func(event string) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			events = append(events, event)
+		}
+
+		failures := 1
+		arrayContainer := container.NewArrayContainer(10, false, func(array []int) error {
+			if failures > 0 {
+				failures--
+				return errors.New("flush failed")
+			}
+			return nil
+		})
+		flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+			ID:                 "hooks-buffer",
+			DisableAutoFlush:   true,
+			FlushRetries:       1,
+			FlushRetryInterval: time.Millisecond,
+			Registry:           buffer.NewRegistry(),
+			Hooks: buffer.Hooks{
+				OnFlushStart: func(info buffer.FlushInfo) {
+					record(fmt.Sprintf("start %s %d", info.Trigger, info.BatchSize))
+				},
+				OnRetry: func(info buffer.FlushInfo) {
+					record(fmt.Sprintf("retry %d %v", info.Retries, info.Err))
+				},
+				OnFlushDone: func(info buffer.FlushInfo) {
+					mutex.Lock()
+					done = info
+					mutex.Unlock()
+					record(fmt.Sprintf("done %s %d %v", info.Trigger, info.Retries, info.Err))
+				},
+				OnDrop: func(id string, reason string, count int) {
+					record(fmt.Sprintf("drop %s %s %d", id, reason, count))
+				},
+				OnClose: func(id string, err error) {
+					record(fmt.Sprintf("close %s %v", id, err))
+				},
+			},
+		})
+		So(err, ShouldBeNil)
+
+		Convey("Hooks should be called around flushes, drops and close", func() {
+			So(flushBuffer.Put(1), ShouldBeNil)
+			So(flushBuffer.Put(2), ShouldBeNil)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+			So(flushBuffer.Shutdown(context.Background()), ShouldBeNil)
+			So(flushBuffer.Put(3), ShouldEqual, buffer.ErrClosed)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			So(events, ShouldResemble, []string{
+				"start manual 2",
+				"retry 0 flush failed",
+				"done manual 1 <nil>",
+				"start close 0",
+				"done close 0 <nil>",
+				"close hooks-buffer <nil>",
+				"drop hooks-buffer closed 1",
+			})
+			So(done.ID, ShouldEqual, "hooks-buffer")
+			So(done.Duration, ShouldBeGreaterThan, 0)
+		})
+	})
+}
+
+// keepingContainer hold data in a slice, and fail every Flush. failed data are kept except the oldest drop elements,
+// reported by *container.KeptError, or by *container.PartialFlushError when partial is true
+type keepingContainer struct {
+	data    []int
+	drop    int
+	partial bool
+}
+
+func (keeper *keepingContainer) Put(element int) error {
+	keeper.data = append(keeper.data, element)
+	return nil
+}
+
+func (keeper *keepingContainer) Flush() error {
+	flushErr, drop := errors.New("sink unavailable"), keeper.drop
+	if drop > len(keeper.data) {
+		drop = len(keeper.data)
+	}
+	if keeper.partial {
+		partial := &container.PartialFlushError[int]{Total: len(keeper.data)}
+		for i, element := range keeper.data {
+			partial.Failed = append(partial.Failed, container.FailedElement[int]{Element: element, Err: flushErr, Requeued: i >= drop})
+		}
+		keeper.data = keeper.data[drop:]
+		return partial
+	}
+	keeper.data = keeper.data[drop:]
+	return &container.KeptError{Dropped: drop, Err: flushErr}
+}
+
+func (keeper *keepingContainer) IsFull() bool { return false }
+func (keeper *keepingContainer) Reset()       { keeper.data = nil }
+func (keeper *keepingContainer) Len() int     { return len(keeper.data) }
+func (keeper *keepingContainer) KeepFailed()  {}
+
+func TestBufferDropHook(t *testing.T) {
+	Convey("Given a Buffer whose container fails to flush", t, func() {
+		var (
+			mutex sync.Mutex
+			drops []int
+		)
+		newBuffer := func(flushContainer container.Container[int]) *buffer.Buffer[int] {
+			flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), flushContainer, buffer.Config{
+				ID:               "drop-buffer",
+				DisableAutoFlush: true,
+				Registry:         buffer.NewRegistry(),
+				Hooks: buffer.Hooks{
+					OnDrop: func(id string, reason string, count int) {
+						if reason == buffer.DropReasonFlushFailed {
+							mutex.Lock()
+							drops = append(drops, count)
+							mutex.Unlock()
+						}
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+			return flushBuffer
+		}
+		flushTwice := func(flushBuffer *buffer.Buffer[int]) {
+			for i := 0; i < 3; i++ {
+				So(flushBuffer.Put(i), ShouldBeNil)
+			}
+			So(flushBuffer.Flush(false), ShouldBeNil)
+			So(flushBuffer.Put(3), ShouldBeNil)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+			flushBuffer.Shutdown(context.Background())
+		}
+
+		Convey("The whole batch should be counted as dropped when the container is reset", func() {
+			flushTwice(newBuffer(container.NewArrayContainer(10, false, func(array []int) error {
+				return errors.New("sink unavailable")
+			})))
+			So(drops, ShouldResemble, []int{3, 1})
+		})
+
+		Convey("Only data not kept by container should be counted as dropped, until the last flush triggered by close fails", func() {
+			flushTwice(newBuffer(&keepingContainer{drop: 1}))
+			So(drops, ShouldResemble, []int{1, 1, 2})
+		})
+
+		Convey("Only failed elements not requeued should be counted as dropped, until the last flush triggered by close fails", func() {
+			flushTwice(newBuffer(&keepingContainer{drop: 1, partial: true}))
+			So(drops, ShouldResemble, []int{1, 1, 2})
+		})
+	})
+}
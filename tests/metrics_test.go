@@ -0,0 +1,109 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	Convey("Given a Buffer recording metrics with PrometheusMetrics", t, func() {
+		metrics, err := buffer.NewPrometheusMetrics(buffer.PrometheusMetricsConfig{})
+		So(err, ShouldBeNil)
+
+		failures := 1
+		arrayContainer := container.NewArrayContainer(10, false, func(array []int) error {
+			if failures > 0 {
+				failures--
+				return errors.New("flush failed")
+			}
+			return nil
+		})
+		flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+			ID:                 "metrics-buffer",
+			DisableAutoFlush:   true,
+			FlushRetries:       2,
+			FlushRetryInterval: time.Millisecond,
+			Metrics:            metrics,
+		})
+		So(err, ShouldBeNil)
+		defer flushBuffer.Close()
+
+		Convey("When putting data and flushing with a retry", func() {
+			for i := 0; i < 3; i++ {
+				So(flushBuffer.Put(i), ShouldBeNil)
+			}
+			time.Sleep(100 * time.Millisecond)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+
+			recorder := httptest.NewRecorder()
+			metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+			body := recorder.Body.String()
+
+			Convey("Metrics should be exposed in Prometheus text format", func() {
+				So(recorder.Header().Get("Content-Type"), ShouldStartWith, "text/plain; version=0.0.4")
+				So(body, ShouldContainSubstring, "# TYPE go_buffer_puts_total counter\n")
+				So(body, ShouldContainSubstring, `go_buffer_puts_total{buffer="metrics-buffer"} 3`)
+				So(body, ShouldContainSubstring, `go_buffer_flushes_total{buffer="metrics-buffer",trigger="manual"} 1`)
+				So(body, ShouldContainSubstring, `go_buffer_flush_retries_total{buffer="metrics-buffer",trigger="manual"} 1`)
+				So(body, ShouldContainSubstring, `go_buffer_flush_batch_size_bucket{buffer="metrics-buffer",le="10"} 1`)
+				So(body, ShouldContainSubstring, `go_buffer_flush_duration_seconds_count{buffer="metrics-buffer",trigger="manual"} 1`)
+				So(body, ShouldContainSubstring, `go_buffer_container_size{buffer="metrics-buffer"} 0`)
+				So(body, ShouldNotContainSubstring, "go_buffer_flush_errors_total")
+			})
+
+			Convey("Series of the buffer should be removed after it is closed", func() {
+				So(flushBuffer.Shutdown(context.Background()), ShouldBeNil)
+
+				recorder := httptest.NewRecorder()
+				metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+				So(recorder.Body.String(), ShouldNotContainSubstring, `buffer="metrics-buffer"`)
+			})
+		})
+	})
+
+	Convey("Given PrometheusMetrics registered to a prometheus.Registry", t, func() {
+		metrics, err := buffer.NewPrometheusMetrics(buffer.PrometheusMetricsConfig{Namespace: "app_buffer"})
+		So(err, ShouldBeNil)
+		registry := prometheus.NewRegistry()
+		So(registry.Register(metrics), ShouldBeNil)
+
+		Convey("Metrics observed by buffer should be gathered by the registry", func() {
+			metrics.ObservePut("registry-buffer")
+			metrics.ObserveDrop("registry-buffer", buffer.DropReasonFlushFailed, 2)
+			metrics.ObserveFlush("registry-buffer", buffer.FlushTriggerManual, 5, time.Millisecond, errors.New("boom"))
+
+			families, err := registry.Gather()
+			So(err, ShouldBeNil)
+			gathered := make(map[string]*dto.MetricFamily)
+			for _, family := range families {
+				gathered[family.GetName()] = family
+			}
+			So(gathered["app_buffer_puts_total"].GetMetric()[0].GetCounter().GetValue(), ShouldEqual, 1)
+			So(gathered["app_buffer_dropped_total"].GetMetric()[0].GetCounter().GetValue(), ShouldEqual, 2)
+			So(gathered["app_buffer_flush_errors_total"].GetMetric()[0].GetLabel(), ShouldHaveLength, 3)
+			So(gathered["app_buffer_flush_batch_size"].GetMetric()[0].GetHistogram().GetSampleSum(), ShouldEqual, 5)
+		})
+
+		Convey("Registering metrics with the same namespace twice should fail", func() {
+			duplicated, err := buffer.NewPrometheusMetrics(buffer.PrometheusMetricsConfig{Namespace: "app_buffer"})
+			So(err, ShouldBeNil)
+			So(registry.Register(duplicated), ShouldNotBeNil)
+		})
+	})
+
+	Convey("FlushErrorReason should classify errors of Container.Flush", t, func() {
+		So(buffer.FlushErrorReason(context.DeadlineExceeded), ShouldEqual, "timeout")
+		So(buffer.FlushErrorReason(&container.PartialFlushError[int]{Total: 1}), ShouldEqual, "partial")
+		So(buffer.FlushErrorReason(&container.HTTPStatusError{StatusCode: 503}), ShouldEqual, "retryable")
+		So(buffer.FlushErrorReason(errors.New("boom")), ShouldEqual, "error")
+	})
+}
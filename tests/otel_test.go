@@ -0,0 +1,129 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordedSpan a span recording its start config, status and attributes
+type recordedSpan struct {
+	trace.Span
+	name   string
+	config trace.SpanConfig
+	attrs  []attribute.KeyValue
+	status codes.Code
+}
+
+func (span *recordedSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	span.attrs = append(span.attrs, attrs...)
+}
+
+func (span *recordedSpan) SetStatus(code codes.Code, description string) { span.status = code }
+
+func (span *recordedSpan) RecordError(err error, options ...trace.EventOption) {}
+
+func (span *recordedSpan) End(options ...trace.SpanEndOption) {}
+
+// recordingTracer a TracerProvider and Tracer recording started spans
+type recordingTracer struct {
+	mutex sync.Mutex
+	spans []*recordedSpan
+}
+
+func (tracer *recordingTracer) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return tracer
+}
+
+func (tracer *recordingTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	span := &recordedSpan{Span: trace.SpanFromContext(ctx), name: name, config: trace.NewSpanStartConfig(options...)}
+	tracer.spans = append(tracer.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (tracer *recordingTracer) recorded() []*recordedSpan {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	return append([]*recordedSpan{}, tracer.spans...)
+}
+
+func TestOTelInstrumentation(t *testing.T) {
+	Convey("Given a Buffer traced by a recording TracerProvider", t, func() {
+		tracer := &recordingTracer{}
+		flushErr := errors.New("flush failed")
+		failing := false
+		arrayContainer := container.NewArrayContainer(10, false, func(array []int) error {
+			if failing {
+				return flushErr
+			}
+			return nil
+		})
+		flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+			ID:               "otel-buffer",
+			DisableAutoFlush: true,
+			TracerProvider:   tracer,
+		})
+		So(err, ShouldBeNil)
+		defer flushBuffer.Close()
+
+		putSpan := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: trace.FlagsSampled,
+		})
+
+		Convey("When putting data with a span and flushing manually", func() {
+			So(flushBuffer.PutContext(trace.ContextWithSpanContext(context.Background(), putSpan), 1), ShouldBeNil)
+			So(flushBuffer.Put(2), ShouldBeNil)
+			time.Sleep(100 * time.Millisecond)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+
+			spans := tracer.recorded()
+			So(spans, ShouldHaveLength, 1)
+
+			Convey("The flush span should carry trigger, batch size and links to the put span", func() {
+				span := spans[0]
+				So(span.name, ShouldEqual, "buffer.flush")
+				So(span.config.Attributes(), ShouldContain, buffer.TriggerKey.String("manual"))
+				So(span.config.Attributes(), ShouldContain, buffer.BatchSizeKey.Int(2))
+				So(span.config.Links(), ShouldHaveLength, 1)
+				So(span.config.Links()[0].SpanContext, ShouldResemble, putSpan)
+				So(span.status, ShouldEqual, codes.Unset)
+			})
+
+			Convey("The span of a failed flush should have error status", func() {
+				failing = true
+				So(flushBuffer.Put(3), ShouldBeNil)
+				time.Sleep(100 * time.Millisecond)
+				So(flushBuffer.Flush(false), ShouldBeNil)
+
+				spans := tracer.recorded()
+				So(spans, ShouldHaveLength, 2)
+				So(spans[1].status, ShouldEqual, codes.Error)
+				So(spans[1].config.Links(), ShouldBeEmpty)
+				So(spans[1].attrs, ShouldContain, buffer.RetriesKey.Int(0))
+			})
+		})
+	})
+
+	Convey("OTelMetrics should be created with a MeterProvider", t, func() {
+		metrics, err := buffer.NewOTelMetrics(metric.NewNoopMeterProvider())
+		So(err, ShouldBeNil)
+		metrics.ObservePut("otel-buffer")
+		metrics.SetQueueDepth("otel-buffer", 1)
+		metrics.ObserveFlush("otel-buffer", buffer.FlushTriggerManual, 1, time.Millisecond, errors.New("flush failed"))
+		metrics.Forget("otel-buffer")
+	})
+}
@@ -0,0 +1,87 @@
+package buffer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBufferPause(t *testing.T) {
+	Convey("Given a paused Buffer with a synchronous ArrayContainer", t, func() {
+		var flushed atomic.Int64
+		arrayContainer := container.NewArrayContainer(2, false, func(array []int) error {
+			flushed.Add(int64(len(array)))
+			return nil
+		})
+		flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+			ID:            "pause-buffer",
+			ChanBufSize:   2,
+			FlushInterval: 10 * time.Millisecond,
+			SyncAutoFlush: true,
+			Registry:      buffer.NewRegistry(),
+		})
+		So(err, ShouldBeNil)
+		So(flushBuffer.Pause(), ShouldBeNil)
+		So(flushBuffer.Paused(), ShouldBeTrue)
+		So(flushBuffer.Stats().State, ShouldEqual, buffer.StatePaused)
+
+		Convey("Data should be held without automatic or size-triggered flushes", func() {
+			for i := 0; i < 4; i++ {
+				So(flushBuffer.Put(i), ShouldBeNil)
+			}
+			time.Sleep(50 * time.Millisecond)
+			So(flushed.Load(), ShouldEqual, 0)
+			stats := flushBuffer.Stats()
+			So(stats.ContainerLen, ShouldEqual, 2)
+			So(stats.QueueDepth, ShouldEqual, 2)
+
+			Convey("Put should block once the container and the channel are full", func() {
+				putDone := make(chan struct{})
+				go func() {
+					flushBuffer.Put(4)
+					close(putDone)
+				}()
+				select {
+				case <-putDone:
+					So("put returned", ShouldBeEmpty)
+				case <-time.After(50 * time.Millisecond):
+				}
+
+				Convey("And Resume should flush the held data and unblock Put", func() {
+					So(flushBuffer.Resume(), ShouldBeNil)
+					So(flushBuffer.Paused(), ShouldBeFalse)
+					<-putDone
+					So(flushBuffer.Shutdown(context.Background()), ShouldBeNil)
+					So(flushed.Load(), ShouldEqual, 5)
+				})
+			})
+		})
+
+		Convey("Shutdown should flush the held data while paused", func() {
+			for i := 0; i < 3; i++ {
+				So(flushBuffer.Put(i), ShouldBeNil)
+			}
+			So(flushBuffer.Shutdown(context.Background()), ShouldBeNil)
+			So(flushed.Load(), ShouldEqual, 3)
+		})
+
+		Convey("Manual flush should still work while paused", func() {
+			So(flushBuffer.Put(1), ShouldBeNil)
+			So(flushBuffer.Flush(false), ShouldBeNil)
+			So(flushed.Load(), ShouldEqual, 1)
+			So(flushBuffer.Paused(), ShouldBeTrue)
+			flushBuffer.Close()
+		})
+
+		Convey("Pause and Resume should fail after the buffer is closed", func() {
+			So(flushBuffer.Shutdown(context.Background()), ShouldBeNil)
+			So(flushBuffer.Pause(), ShouldEqual, buffer.ErrClosed)
+			So(flushBuffer.Resume(), ShouldEqual, buffer.ErrClosed)
+		})
+	})
+}
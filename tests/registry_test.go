@@ -0,0 +1,102 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegistry(t *testing.T) {
+	Convey("Given two buffers registered into a Registry", t, func() {
+		registry := buffer.NewRegistry()
+		newBuffer := func(id string) *buffer.Buffer[int] {
+			arrayContainer := container.NewArrayContainer(10, false, func(array []int) error { return nil })
+			flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+				ID:               id,
+				DisableAutoFlush: true,
+				Registry:         registry,
+			})
+			So(err, ShouldBeNil)
+			return flushBuffer
+		}
+		first, second := newBuffer("first"), newBuffer("second")
+		So(first.Put(1), ShouldBeNil)
+		So(second.Put(2), ShouldBeNil)
+		So(second.Put(3), ShouldBeNil)
+
+		So(registry.Buffers(), ShouldHaveLength, 2)
+		So(registry.Get("first"), ShouldEqual, first)
+
+		Convey("FlushAll should flush every buffer", func() {
+			So(registry.FlushAll(context.Background()), ShouldBeNil)
+			So(first.Stats().FlushedRecords, ShouldEqual, 1)
+			So(second.Stats().FlushedRecords, ShouldEqual, 2)
+			So(registry.CloseAll(context.Background()), ShouldBeNil)
+		})
+
+		Convey("The handler of registry should list registered buffers", func() {
+			recorder := httptest.NewRecorder()
+			registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/buffers", nil))
+			So(recorder.Code, ShouldEqual, http.StatusOK)
+			So(recorder.Body.String(), ShouldContainSubstring, `"id":"first"`)
+			So(recorder.Body.String(), ShouldContainSubstring, `"id":"second"`)
+			So(registry.CloseAll(context.Background()), ShouldBeNil)
+		})
+
+		Convey("CloseAll should wait for every buffer to drain and unregister them", func() {
+			So(registry.CloseAll(context.Background()), ShouldBeNil)
+			So(first.Stats().State, ShouldEqual, buffer.StateClosed)
+			So(second.Stats().State, ShouldEqual, buffer.StateClosed)
+			So(first.Stats().FlushedRecords+second.Stats().FlushedRecords, ShouldEqual, 3)
+			So(registry.Buffers(), ShouldBeEmpty)
+		})
+
+		Convey("NewBuffer should fail with an ID already registered", func() {
+			arrayContainer := container.NewArrayContainer(10, false, func(array []int) error { return nil })
+			config := buffer.Config{ID: "first", DisableAutoFlush: true, Registry: registry}
+			duplicated, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, config)
+			So(errors.Is(err, buffer.ErrDuplicateID), ShouldBeTrue)
+			So(duplicated, ShouldBeNil)
+			So(registry.Get("first"), ShouldEqual, first)
+
+			Convey("And the ID can be used again after the buffer is closed", func() {
+				So(first.Shutdown(context.Background()), ShouldBeNil)
+				reused, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, config)
+				So(err, ShouldBeNil)
+				So(registry.Get("first"), ShouldEqual, reused)
+				So(registry.CloseAll(context.Background()), ShouldBeNil)
+			})
+		})
+
+		Convey("NewBuffer without Config.Registry should replace the buffer with the same ID in DefaultRegistry", func() {
+			newDefault := func() *buffer.Buffer[int] {
+				arrayContainer := container.NewArrayContainer(10, false, func(array []int) error { return nil })
+				flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{ID: "default-buffer", DisableAutoFlush: true})
+				So(err, ShouldBeNil)
+				return flushBuffer
+			}
+			old, replacing := newDefault(), newDefault()
+			So(buffer.DefaultRegistry.Get("default-buffer"), ShouldEqual, replacing)
+
+			So(old.Shutdown(context.Background()), ShouldBeNil)
+			So(buffer.DefaultRegistry.Get("default-buffer"), ShouldEqual, replacing)
+			So(replacing.Shutdown(context.Background()), ShouldBeNil)
+			So(buffer.DefaultRegistry.Get("default-buffer"), ShouldBeNil)
+			So(registry.CloseAll(context.Background()), ShouldBeNil)
+		})
+
+		Convey("CloseOnSignal should close every buffer when ctx is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			So(registry.CloseOnSignal(ctx, time.Second), ShouldBeNil)
+			So(registry.Buffers(), ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBufferShutdown(t *testing.T) {
+	Convey("Given a Buffer flushing asynchronously with a slow container", t, func() {
+		var flushed atomic.Int64
+		flushErr := errors.New("flush failed")
+		var failing atomic.Bool
+		delay := 100 * time.Millisecond
+		arrayContainer := container.NewArrayContainer(5, false, func(array []int) error {
+			time.Sleep(delay)
+			if failing.Load() {
+				return flushErr
+			}
+			flushed.Add(int64(len(array)))
+			return nil
+		})
+		flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+			ID:               "shutdown-buffer",
+			DisableAutoFlush: true,
+			Registry:         buffer.NewRegistry(),
+		})
+		So(err, ShouldBeNil)
+		for i := 0; i < 7; i++ {
+			So(flushBuffer.Put(i), ShouldBeNil)
+		}
+
+		Convey("Shutdown should wait for in-flight flushes, the drain and the last flush", func() {
+			So(flushBuffer.Shutdown(context.Background()), ShouldBeNil)
+			So(flushed.Load(), ShouldEqual, 7)
+			So(flushBuffer.Stats().State, ShouldEqual, buffer.StateClosed)
+
+			Convey("Shutdown again should return immediately", func() {
+				So(flushBuffer.Shutdown(context.Background()), ShouldBeNil)
+				So(flushBuffer.Put(7), ShouldEqual, buffer.ErrClosed)
+			})
+		})
+
+		Convey("Shutdown should return the error of the last flush", func() {
+			failing.Store(true)
+			err := flushBuffer.Shutdown(context.Background())
+			So(errors.Is(err, flushErr), ShouldBeTrue)
+		})
+
+		Convey("Shutdown should return when ctx is done before the buffer is drained", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			err := flushBuffer.Shutdown(ctx)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			<-flushBuffer.Done()
+		})
+	})
+}
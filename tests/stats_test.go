@@ -0,0 +1,108 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kevinello/go-buffer"
+	"github.com/Kevinello/go-buffer/container"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBufferStats(t *testing.T) {
+	Convey("Given a Buffer with a synchronous ArrayContainer", t, func() {
+		flushErr := errors.New("flush failed")
+		failing := false
+		arrayContainer := container.NewArrayContainer(10, false, func(array []int) error {
+			if failing {
+				return flushErr
+			}
+			return nil
+		})
+		flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+			ID:               "stats-buffer",
+			DisableAutoFlush: true,
+		})
+		So(err, ShouldBeNil)
+
+		Convey("A new buffer should be running without flushes", func() {
+			stats := flushBuffer.Stats()
+			So(stats.ID, ShouldEqual, "stats-buffer")
+			So(stats.State, ShouldEqual, buffer.StateRunning)
+			So(stats.ContainerLen, ShouldEqual, 0)
+			So(stats.ContainerBytes, ShouldEqual, -1)
+			So(stats.LastFlushTime.IsZero(), ShouldBeTrue)
+			flushBuffer.Close()
+		})
+
+		Convey("When putting data and flushing synchronously", func() {
+			for i := 0; i < 3; i++ {
+				So(flushBuffer.Put(i), ShouldBeNil)
+			}
+			// a synchronous flush carries the data put before it
+			So(flushBuffer.Flush(false), ShouldBeNil)
+
+			stats := flushBuffer.Stats()
+			So(stats.ContainerLen, ShouldEqual, 0)
+			So(stats.FlushedRecords, ShouldEqual, 3)
+			So(stats.LastFlushTime.IsZero(), ShouldBeFalse)
+			So(stats.LastFlushError, ShouldBeNil)
+			So(stats.InFlightFlushes, ShouldEqual, 0)
+
+			Convey("A failed flush should be recorded as the last flush error", func() {
+				failing = true
+				So(flushBuffer.Put(3), ShouldBeNil)
+				So(flushBuffer.Flush(false), ShouldBeNil)
+
+				stats := flushBuffer.Stats()
+				So(stats.LastFlushError, ShouldEqual, flushErr)
+				So(stats.FlushedRecords, ShouldEqual, 3)
+				flushBuffer.Close()
+			})
+
+			Convey("The buffer should be closed after Close", func() {
+				So(flushBuffer.Close(), ShouldBeNil)
+				So(flushBuffer.Stats().State, ShouldNotEqual, buffer.StateRunning)
+				for i := 0; i < 100 && flushBuffer.Stats().State != buffer.StateClosed; i++ {
+					time.Sleep(10 * time.Millisecond)
+				}
+				So(flushBuffer.Stats().State, ShouldEqual, buffer.StateClosed)
+			})
+		})
+	})
+
+	Convey("Given a Buffer flushing a full container asynchronously", t, func() {
+		release := make(chan struct{})
+		batchSizes := make(chan int, 1)
+		arrayContainer := container.NewArrayContainer(2, false, func(array []int) error {
+			<-release
+			return nil
+		})
+		flushBuffer, _, err := buffer.NewBuffer[int](context.Background(), arrayContainer, buffer.Config{
+			ID:               "async-stats-buffer",
+			DisableAutoFlush: true,
+			Registry:         buffer.NewRegistry(),
+			Hooks: buffer.Hooks{
+				OnFlushStart: func(info buffer.FlushInfo) { batchSizes <- info.BatchSize },
+			},
+		})
+		So(err, ShouldBeNil)
+		defer flushBuffer.Close()
+		So(flushBuffer.Put(1), ShouldBeNil)
+		So(flushBuffer.Put(2), ShouldBeNil)
+
+		Convey("The batch size should be taken before the flush, and stats of container updated after it is done", func() {
+			So(<-batchSizes, ShouldEqual, 2)
+			So(flushBuffer.Stats().InFlightFlushes, ShouldEqual, 1)
+			close(release)
+			for i := 0; i < 100 && flushBuffer.Stats().ContainerLen != 0; i++ {
+				time.Sleep(10 * time.Millisecond)
+			}
+			stats := flushBuffer.Stats()
+			So(stats.ContainerLen, ShouldEqual, 0)
+			So(stats.FlushedRecords, ShouldEqual, 2)
+		})
+	})
+}